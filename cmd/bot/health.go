@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/app/health"
+)
+
+const (
+	// telegramStaleAfter must be larger than the long polling timeout.
+	telegramStaleAfter = 3 * time.Minute
+	// telegramUnreadyAfter takes the instance out of service before it gets restarted.
+	telegramUnreadyAfter = time.Minute + pollRetryDelay
+	// queueSaturationLimit is the queue fill ratio at which the instance stops being ready.
+	queueSaturationLimit = 0.9
+)
+
+func telegramCheck(p *poller, staleAfter time.Duration) health.CheckFunc {
+	return func() error {
+		if since := time.Since(p.LastSuccess()); since > staleAfter {
+			return fmt.Errorf("last successful getUpdates %v ago", since.Round(time.Second))
+		}
+
+		return nil
+	}
+}
+
+// queue is implemented by worker.Pool and sender.Dispatcher.
+type queue interface {
+	Saturation() float64
+}
+
+func queueCheck(q queue) health.CheckFunc {
+	return func() error {
+		if saturation := q.Saturation(); saturation >= queueSaturationLimit {
+			return fmt.Errorf("queue is %.0f%% full", saturation*100)
+		}
+
+		return nil
+	}
+}
+
+// serveHTTP starts the server in the background, it is stopped with Shutdown.
+func serveHTTP(addr string, mux *http.ServeMux) *http.Server {
+	server := &http.Server{Addr: addr, Handler: mux}
+	log.Printf("HTTP server listening on %s", addr)
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("HTTP server stopped - %v", err)
+		}
+	}()
+
+	return server
+}
@@ -1,12 +1,42 @@
 package main
 
 import (
+	"context"
+	"expvar"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/joho/godotenv"
+	"github.com/ozonmp/omp-bot/cmd/internal/botconfig"
+	"github.com/ozonmp/omp-bot/internal/app/admin"
+	"github.com/ozonmp/omp-bot/internal/app/commands/insurance"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/health"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/middleware"
+	"github.com/ozonmp/omp-bot/internal/app/recorder"
+	"github.com/ozonmp/omp-bot/internal/app/reminder"
 	routerPkg "github.com/ozonmp/omp-bot/internal/app/router"
+	"github.com/ozonmp/omp-bot/internal/app/scheduler"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+	"github.com/ozonmp/omp-bot/internal/app/settings"
+	"github.com/ozonmp/omp-bot/internal/app/telegram"
+	"github.com/ozonmp/omp-bot/internal/app/watch"
+	"github.com/ozonmp/omp-bot/internal/app/worker"
+	"github.com/ozonmp/omp-bot/internal/events"
+	"github.com/ozonmp/omp-bot/internal/events/outbox"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+	claimService "github.com/ozonmp/omp-bot/internal/service/insurance/claim"
+	policyService "github.com/ozonmp/omp-bot/internal/service/insurance/policy"
+	"github.com/ozonmp/omp-bot/internal/service/insurance/rating"
+	"github.com/ozonmp/omp-bot/internal/storage"
 )
 
 func main() {
@@ -17,7 +47,7 @@ func main() {
 		log.Panic("environment variable TOKEN not found in .env")
 	}
 
-	bot, err := tgbotapi.NewBotAPI(token)
+	bot, err := telegram.NewBotAPI(token, os.Getenv("TELEGRAM_API_ENDPOINT"))
 	if err != nil {
 		log.Panic(err)
 	}
@@ -31,14 +61,259 @@ func main() {
 		Timeout: 60,
 	}
 
-	updates, err := bot.GetUpdatesChan(u)
+	dataDir := os.Getenv("DATA_DIR")
+	store, err := storage.Open(dataDir)
 	if err != nil {
 		log.Panic(err)
 	}
+	defer store.Close()
 
-	routerHandler := routerPkg.NewRouter(bot)
+	// events of the car changes are kept in the outbox until every sink has them
+	eventOutbox := outbox.New(store)
 
-	for update := range updates {
-		routerHandler.HandleUpdate(update)
+	// events of the car changes are recorded in the transactions of the changes, so the storage keeps the cars
+	// even without a data directory, it is filled with sample cars then
+	stored := carService.NewStoredCarService(store, eventOutbox)
+	var carSvc interface {
+		carService.CarService
+		Ping() error
+	} = stored
+	var cars carService.CarService = stored
+	var policySvc policyService.PolicyService = policyService.NewMemoryPolicyService()
+	var claimSvc claimService.ClaimService = claimService.NewMemoryClaimService()
+	if dataDir != "" {
+		policySvc = policyService.NewStoredPolicyService(store)
+		claimSvc = claimService.NewStoredClaimService(store)
+	} else if err := stored.Seed(carService.SampleCars()...); err != nil {
+		log.Panic(err)
+	}
+	// the car server is the source of truth for cars when it is configured, it records the events itself
+	// and posts them to the events endpoint of the bot
+	carAddr := os.Getenv("CAR_SERVICE_ADDR")
+	if carAddr != "" {
+		remote, err := carService.DialRemoteCarService(carAddr, carService.RemoteConfig{
+			Timeout:  time.Duration(envInt("CAR_SERVICE_TIMEOUT_MS", 2000)) * time.Millisecond,
+			Attempts: envInt("CAR_SERVICE_ATTEMPTS", carService.DefaultRemoteConfig.Attempts),
+			Backoff:  carService.DefaultRemoteConfig.Backoff,
+		})
+		if err != nil {
+			log.Panicf("CAR_SERVICE_ADDR: %v", err)
+		}
+		defer remote.Close()
+
+		log.Printf("Using the car service at %s", carAddr)
+		carSvc, cars = remote, remote
+	}
+	// the cache saves calls to the car server, the local store is read as fast as the cache itself
+	var carCache *carService.CachingCarService
+	defaultTTL := 0
+	if carAddr != "" {
+		defaultTTL = int(carService.DefaultCacheConfig.TTL / time.Second)
+	}
+	if ttl := time.Duration(envInt("CAR_CACHE_TTL_SECONDS", defaultTTL)) * time.Second; ttl > 0 {
+		carCache = carService.NewCachingCarService(cars, carService.CacheConfig{
+			TTL:  ttl,
+			Size: envInt("CAR_CACHE_SIZE", carService.DefaultCacheConfig.Size),
+		})
+		cars = carCache
+	}
+	userSettings := settings.NewStore(store)
+
+	rates := rating.DefaultTables()
+	if ratesFile := os.Getenv("RATE_TABLES"); ratesFile != "" {
+		rates, err = rating.LoadTables(ratesFile)
+		if err != nil {
+			log.Panic(err)
+		}
+	}
+
+	dispatcher := sender.NewDispatcher(
+		sender.NewThrottled(bot),
+		envInt("SENDER_WORKERS", 4),
+		envInt("SENDER_QUEUE_SIZE", 100),
+		sender.DefaultBackoff,
+	)
+
+	var botSender sender.Sender = dispatcher
+	middlewares := middlewaresFromConfig(envString("MIDDLEWARES", "logging,metrics,ratelimit"))
+
+	if recordFile := os.Getenv("RECORD_FILE"); recordFile != "" {
+		rec, err := recorder.New(recordFile, int64(envInt("RECORD_MAX_MB", 50))<<20, envInt("RECORD_BACKUPS", 5))
+		if err != nil {
+			log.Panic(err)
+		}
+		defer rec.Close()
+
+		log.Printf("Recording updates to %s", recordFile)
+		botSender = rec.Sender(botSender)
+		middlewares = append([]middleware.Middleware{rec.Middleware()}, middlewares...)
+	}
+
+	localizer := i18n.NewLocalizer(i18n.Default, envString("DEFAULT_LANGUAGE", "ru"), userSettings)
+
+	bus := events.NewBus(envInt("EVENT_QUEUE_SIZE", 100))
+	watches := watch.NewStore(store)
+	if carCache != nil {
+		// changes of other clients of the car server come as events, the cache must not outlive them
+		bus.Subscribe(carCache.Handle)
+	}
+	bus.Subscribe(watch.NewNotifier(botSender, watches, userSettings, localizer).Handle)
+
+	sinks, err := botconfig.Sinks()
+	if err != nil {
+		log.Panic(err)
+	}
+	busSink := outbox.NewBusSink(bus)
+	relay := outbox.NewRelay(eventOutbox, botconfig.RelayConfig(), append([]outbox.Sink{busSink}, sinks...)...)
+	relay.Start()
+
+	roles, err := botconfig.Roles()
+	if err != nil {
+		log.Panic(err)
+	}
+	aliases, err := botconfig.Aliases()
+	if err != nil {
+		log.Panic(err)
+	}
+	routerHandler := routerPkg.NewRouter(
+		botSender,
+		insurance.Services{
+			Car:     cars,
+			Policy:  policySvc,
+			Claim:   claimSvc,
+			Rating:  rating.NewTableEngine(rates),
+			Watches: watches,
+		},
+		localizer,
+		userSettings,
+		routerPkg.Config{
+			Roles:       roles,
+			Aliases:     aliases,
+			BotUserName: bot.Self.UserName,
+			Middlewares: middlewares,
+			Answerer:    bot,
+		},
+	)
+
+	if err := meta.Publish(bot, routerHandler.Commands(), roles.Default, localizer); err != nil {
+		log.Printf("cannot publish the command menu - %v", err)
+	}
+
+	chats, err := envInt64List("EXPIRY_CHATS")
+	if err != nil {
+		log.Panicf("EXPIRY_CHATS: %v", err)
 	}
+	jobs := scheduler.New(store)
+	jobs.Add(
+		"expiry_reminders",
+		time.Duration(envInt("EXPIRY_SCAN_MINUTES", 60))*time.Minute,
+		reminder.NewExpiryReminder(botSender, policySvc, carSvc, userSettings, localizer, store, envInt("EXPIRY_REMINDER_DAYS", 7), chats),
+	)
+	jobs.Start()
+
+	pool := worker.NewPool(routerHandler, envInt("WORKERS", 1), envInt("QUEUE_SIZE", 100))
+	updates := newPoller(bot, u)
+
+	checks := health.NewRegistry()
+	checks.AddLiveness("telegram", telegramCheck(updates, telegramStaleAfter))
+	checks.AddReadiness("telegram", telegramCheck(updates, telegramUnreadyAfter))
+	// the settings, outbox, watches and jobs share the store with the local cars, the car server is checked apart
+	checks.AddReadiness("storage", store.Ping)
+	if carAddr != "" {
+		checks.AddReadiness("car_service", carSvc.Ping)
+	}
+	checks.AddReadiness("workers", queueCheck(pool))
+	checks.AddReadiness("sender", queueCheck(dispatcher))
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", checks.LivenessHandler())
+	mux.Handle("/readyz", checks.ReadinessHandler())
+	mux.Handle("/debug/vars", expvar.Handler())
+	if tokens := adminTokens(); !tokens.Empty() {
+		// the API changes cars through the same service as the commanders, so watchers and sinks see the changes
+		mux.Handle(admin.Prefix, admin.NewAPI(cars, tokens))
+		log.Printf("Admin API is served under %s", admin.Prefix)
+	}
+	if carAddr != "" {
+		mux.Handle("/events", outbox.NewWebhookReceiver(os.Getenv("CAR_EVENTS_SECRET"), busSink))
+	}
+	httpServer := serveHTTP(envString("HTTP_ADDR", ":8080"), mux)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	updates.Run(ctx, func(update tgbotapi.Update) {
+		if err := pool.Submit(update); err != nil {
+			log.Printf("error queueing update %d - %v", update.UpdateID, err)
+		}
+	})
+
+	// the producers are stopped before the queues they feed, the store is closed by the deferred call last
+	log.Print("Stopping")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server did not stop in time - %v", err)
+	}
+	pool.Close()
+	jobs.Stop()
+	relay.Stop()
+	bus.Close()
+	dispatcher.Close()
+}
+
+// httpShutdownTimeout is how long the requests in progress are waited for on shutdown.
+const httpShutdownTimeout = 5 * time.Second
+
+func envString(key, fallback string) string {
+	if value, found := os.LookupEnv(key); found && value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func envInt(key string, fallback int) int {
+	value, found := os.LookupEnv(key)
+	if !found || value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("environment variable %s=%q is not a number, using %d", key, value, fallback)
+		return fallback
+	}
+
+	return parsed
+}
+
+// adminTokens reads the tokens of the admin API, without tokens the API is disabled.
+func adminTokens() *admin.Tokens {
+	tokens := admin.NewTokens()
+	if err := tokens.Add(admin.AccessWrite, os.Getenv("ADMIN_TOKENS")); err != nil {
+		log.Panicf("ADMIN_TOKENS: %v", err)
+	}
+	if err := tokens.Add(admin.AccessRead, os.Getenv("ADMIN_READ_TOKENS")); err != nil {
+		log.Panicf("ADMIN_READ_TOKENS: %v", err)
+	}
+
+	return tokens
+}
+
+// envInt64List parses a comma separated list of numbers, e.g. chat IDs.
+func envInt64List(key string) ([]int64, error) {
+	var values []int64
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
 }
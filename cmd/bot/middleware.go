@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/ozonmp/omp-bot/internal/app/middleware"
+	"github.com/ozonmp/omp-bot/internal/app/ratelimit"
+)
+
+// availableMiddlewares lists middlewares which can be enabled with the MIDDLEWARES variable.
+var availableMiddlewares = map[string]func() middleware.Middleware{
+	"logging": middleware.Logging,
+	"metrics": middleware.Metrics,
+	"ratelimit": func() middleware.Middleware {
+		return ratelimit.Middleware(ratelimit.Config{
+			User: rateLimitRules("RATE_LIMITS_USER", "default=2/s:5,new__insurance__car=5/m:3"),
+			Chat: rateLimitRules("RATE_LIMITS_CHAT", "default=10/s:30"),
+		})
+	},
+}
+
+func rateLimitRules(key, fallback string) map[string]ratelimit.Rule {
+	rules, err := ratelimit.ParseRules(envString(key, fallback))
+	if err != nil {
+		log.Printf("environment variable %s is malformed, using %q - %v", key, fallback, err)
+		rules, _ = ratelimit.ParseRules(fallback)
+	}
+
+	return rules
+}
+
+// middlewaresFromConfig builds the chain from a comma separated list of names.
+func middlewaresFromConfig(names string) []middleware.Middleware {
+	var chain []middleware.Middleware
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		constructor, found := availableMiddlewares[name]
+		if !found {
+			log.Printf("unknown middleware %q is skipped", name)
+			continue
+		}
+		chain = append(chain, constructor())
+	}
+
+	return chain
+}
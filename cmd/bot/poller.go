@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const pollRetryDelay = 3 * time.Second
+
+// poller is a replacement for BotAPI.GetUpdatesChan which remembers
+// when Telegram was reachable last time.
+type poller struct {
+	bot    *tgbotapi.BotAPI
+	config tgbotapi.UpdateConfig
+
+	lastSuccess int64
+}
+
+// newPoller expects the bot to be just authorized, so Telegram counts as reachable at start.
+func newPoller(bot *tgbotapi.BotAPI, config tgbotapi.UpdateConfig) *poller {
+	return &poller{
+		bot:         bot,
+		config:      config,
+		lastSuccess: time.Now().UnixNano(),
+	}
+}
+
+// Run hands the updates to handle until the context is cancelled.
+func (p *poller) Run(ctx context.Context, handle func(update tgbotapi.Update)) {
+	for ctx.Err() == nil {
+		updates, err := p.getUpdates(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("poller: failed to get updates, retrying in %v - %v", pollRetryDelay, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(pollRetryDelay):
+			}
+
+			continue
+		}
+		atomic.StoreInt64(&p.lastSuccess, time.Now().UnixNano())
+
+		for _, update := range updates {
+			if update.UpdateID >= p.config.Offset {
+				p.config.Offset = update.UpdateID + 1
+				handle(update)
+			}
+		}
+	}
+}
+
+// getUpdates returns as soon as the context is cancelled. Updates of an abandoned long poll are not
+// confirmed to Telegram, so they are received again after a restart.
+func (p *poller) getUpdates(ctx context.Context) ([]tgbotapi.Update, error) {
+	type result struct {
+		updates []tgbotapi.Update
+		err     error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		updates, err := p.bot.GetUpdates(p.config)
+		done <- result{updates: updates, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.updates, r.err
+	}
+}
+
+// LastSuccess returns the time of the last successful getUpdates call.
+func (p *poller) LastSuccess() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&p.lastSuccess))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ozonmp/omp-bot/cmd/internal/botconfig"
+	"github.com/ozonmp/omp-bot/internal/events/outbox"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+	"github.com/ozonmp/omp-bot/internal/storage"
+	carpb "github.com/ozonmp/omp-bot/pkg/insurance/car/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// car-server is the reference gRPC backend of the bot, it keeps the cars in DATA_DIR or the sample cars in memory.
+// Events of the changes are recorded with the changes and delivered to the sinks of OUTBOX_SINKS,
+// the webhook sink pointed to the /events endpoint of the bot gets them to the watchers.
+// Run the bot with CAR_SERVICE_ADDR pointing to it.
+func main() {
+	addr := os.Getenv("GRPC_ADDR")
+	if addr == "" {
+		addr = ":50051"
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dataDir := os.Getenv("DATA_DIR")
+	store, err := storage.Open(dataDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	eventOutbox := outbox.New(store)
+	cars := carService.NewStoredCarService(store, eventOutbox)
+	if dataDir == "" {
+		if err := cars.Seed(carService.SampleCars()...); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	sinks, err := botconfig.Sinks()
+	if err != nil {
+		log.Fatal(err)
+	}
+	relay := outbox.NewRelay(eventOutbox, botconfig.RelayConfig(), sinks...)
+	relay.Start()
+	defer relay.Stop()
+
+	server := grpc.NewServer()
+	carpb.RegisterCarServiceServer(server, carService.NewServer(cars))
+	checks := health.NewServer()
+	checks.SetServingStatus(carService.HealthService, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, checks)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		log.Print("stopping")
+		checks.Shutdown()
+		server.GracefulStop()
+	}()
+
+	log.Printf("Serving cars over gRPC on %s", listener.Addr())
+	if err := server.Serve(listener); err != nil {
+		log.Print(err)
+	}
+}
@@ -0,0 +1,108 @@
+// Package botconfig reads the configuration shared by the commands from the environment:
+// cmd/replay routes updates like cmd/bot does, cmd/car-server delivers events to the same sinks.
+package botconfig
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/events/outbox"
+)
+
+const defaultAliases = "cars=list__insurance__car,car=get__insurance__car"
+
+// Roles reads roles from DEFAULT_ROLE, AGENT_IDS and ADMIN_IDS.
+func Roles() (*auth.StaticResolver, error) {
+	defaultRole, err := auth.ParseRole(env("DEFAULT_ROLE", "agent"))
+	if err != nil {
+		return nil, fmt.Errorf("DEFAULT_ROLE: %w", err)
+	}
+
+	resolver := auth.NewStaticResolver(defaultRole)
+	if err := resolver.Grant(auth.RoleAgent, env("AGENT_IDS", "")); err != nil {
+		return nil, fmt.Errorf("AGENT_IDS: %w", err)
+	}
+	if err := resolver.Grant(auth.RoleAdmin, env("ADMIN_IDS", "")); err != nil {
+		return nil, fmt.Errorf("ADMIN_IDS: %w", err)
+	}
+
+	return resolver, nil
+}
+
+// Aliases reads short command names from COMMAND_ALIASES.
+func Aliases() (path.Aliases, error) {
+	aliases, err := path.ParseAliases(env("COMMAND_ALIASES", defaultAliases))
+	if err != nil {
+		return nil, fmt.Errorf("COMMAND_ALIASES: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// Sinks builds the outbox sinks listed in OUTBOX_SINKS, the bus sink is added by the bot itself.
+// The broker sink needs a producer of a broker client and is enabled in code.
+func Sinks() ([]outbox.Sink, error) {
+	var sinks []outbox.Sink
+	for _, name := range strings.Split(os.Getenv("OUTBOX_SINKS"), ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+		case "log":
+			sinks = append(sinks, outbox.LogSink{})
+		case "file":
+			sink, err := outbox.NewFileSink(env("OUTBOX_FILE", "events.jsonl"))
+			if err != nil {
+				return nil, fmt.Errorf("OUTBOX_FILE: %w", err)
+			}
+			sinks = append(sinks, sink)
+		case "webhook":
+			url := env("OUTBOX_WEBHOOK_URL", "")
+			if url == "" {
+				return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required by the webhook sink")
+			}
+			timeout := time.Duration(envInt("OUTBOX_WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second
+			sinks = append(sinks, outbox.NewWebhookSink(url, env("OUTBOX_WEBHOOK_SECRET", ""), timeout))
+		default:
+			log.Printf("unknown outbox sink %q is skipped", name)
+		}
+	}
+
+	return sinks, nil
+}
+
+// RelayConfig reads how often the outbox is polled from OUTBOX_POLL_MS.
+func RelayConfig() outbox.Config {
+	config := outbox.DefaultConfig
+	config.Poll = time.Duration(envInt("OUTBOX_POLL_MS", 1000)) * time.Millisecond
+
+	return config
+}
+
+func env(key, fallback string) string {
+	if value, found := os.LookupEnv(key); found && value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func envInt(key string, fallback int) int {
+	value := env(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("environment variable %s=%q is not a number, using %d", key, value, fallback)
+		return fallback
+	}
+
+	return parsed
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ozonmp/omp-bot/cmd/internal/botconfig"
+	"github.com/ozonmp/omp-bot/internal/app/commands/insurance"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/recorder"
+	routerPkg "github.com/ozonmp/omp-bot/internal/app/router"
+	"github.com/ozonmp/omp-bot/internal/app/settings"
+	"github.com/ozonmp/omp-bot/internal/app/watch"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+	claimService "github.com/ozonmp/omp-bot/internal/service/insurance/claim"
+	policyService "github.com/ozonmp/omp-bot/internal/service/insurance/policy"
+	"github.com/ozonmp/omp-bot/internal/service/insurance/rating"
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+// replay feeds a recording made by cmd/bot through the router and reports the replies which differ.
+func main() {
+	file := flag.String("file", "recording.jsonl", "recording to replay")
+	quiet := flag.Bool("quiet", false, "print only the differing steps")
+	botUserName := flag.String("bot", "", "user name of the recorded bot, commands addressed to other bots are ignored")
+	flag.Parse()
+
+	recording, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer recording.Close()
+
+	steps, err := recorder.ReadSteps(recording)
+	if err != nil {
+		log.Fatalf("cannot read %s: %v", *file, err)
+	}
+
+	// roles and aliases are read from the same variables as in cmd/bot
+	roles, err := botconfig.Roles()
+	if err != nil {
+		log.Fatal(err)
+	}
+	aliases, err := botconfig.Aliases()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	collector := &recorder.Collector{}
+	language := os.Getenv("DEFAULT_LANGUAGE")
+	if language == "" {
+		language = "ru"
+	}
+	store := storage.NewMemoryStore()
+	userSettings := settings.NewStore(store)
+	localizer := i18n.NewLocalizer(i18n.Default, language, userSettings)
+	router := routerPkg.NewRouter(collector, insurance.Services{
+		Car:    carService.NewDummyCarService(),
+		Policy: policyService.NewMemoryPolicyService(),
+		Claim:  claimService.NewMemoryClaimService(),
+		Rating: rating.NewTableEngine(rating.DefaultTables()),
+		// change notifications are sent asynchronously and are not replayed
+		Watches: watch.NewStore(store),
+	}, localizer, userSettings, routerPkg.Config{
+		Roles:       roles,
+		Aliases:     aliases,
+		BotUserName: *botUserName,
+	})
+
+	failed := 0
+	for _, step := range steps {
+		router.HandleUpdate(step.Update)
+
+		diffs := recorder.Diff(step.Outputs, collector.Take())
+		if len(diffs) == 0 {
+			if !*quiet {
+				fmt.Printf("ok   line %d update %d\n", step.Line, step.Update.UpdateID)
+			}
+			continue
+		}
+
+		failed++
+		fmt.Printf("DIFF line %d update %d\n", step.Line, step.Update.UpdateID)
+		for _, diff := range diffs {
+			fmt.Printf("     %s\n", diff)
+		}
+	}
+
+	fmt.Printf("%d steps replayed, %d differ\n", len(steps), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
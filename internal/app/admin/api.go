@@ -0,0 +1,357 @@
+// Package admin is the HTTP JSON API for back-office tools, it works with the same services as the bot.
+package admin
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+)
+
+// Prefix is where the API is mounted.
+const Prefix = "/api/v1/"
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+	// scanPageSize is the page size used to read cars when the list is filtered.
+	scanPageSize = 100
+	maxBodySize  = 64 << 10
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// requestMetrics counts requests by status code.
+var requestMetrics = expvar.NewMap("admin_requests")
+
+// API serves the cars:
+//
+//	GET    /api/v1/cars?cursor=&limit=&order=&make=&year=&title=
+//	POST   /api/v1/cars
+//	GET    /api/v1/cars/{id}
+//	PUT    /api/v1/cars/{id}
+//	DELETE /api/v1/cars/{id}
+//	GET    /api/v1/openapi.yaml
+type API struct {
+	cars   carService.CarService
+	tokens *Tokens
+}
+
+func NewAPI(cars carService.CarService, tokens *Tokens) *API {
+	return &API{cars: cars, tokens: tokens}
+}
+
+// apiError is written with the status code, Error is safe to show to API users.
+type apiError struct {
+	Status int    `json:"-"`
+	Error  string `json:"error"`
+}
+
+// created marks the body of a response to a POST which has made a new resource.
+type created struct {
+	value interface{}
+}
+
+func errorf(status int, format string, args ...interface{}) *apiError {
+	return &apiError{Status: status, Error: fmt.Sprintf(format, args...)}
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resource := strings.TrimPrefix(r.URL.Path, Prefix)
+	if resource == "openapi.yaml" && r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(openAPISpec)
+		requestMetrics.Add(strconv.Itoa(http.StatusOK), 1)
+		return
+	}
+
+	switch access := a.tokens.Access(r); {
+	case access == AccessNone:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		a.write(w, nil, errorf(http.StatusUnauthorized, "a valid bearer token is required"))
+		return
+	case access == AccessRead && r.Method != http.MethodGet:
+		a.write(w, nil, errorf(http.StatusForbidden, "the token is read-only"))
+		return
+	}
+
+	body, err := a.route(r, resource)
+	a.write(w, body, err)
+}
+
+func (a *API) route(r *http.Request, resource string) (interface{}, *apiError) {
+	parts := strings.Split(strings.Trim(resource, "/"), "/")
+	if parts[0] != "cars" || len(parts) > 2 {
+		return nil, errorf(http.StatusNotFound, "unknown resource %q", resource)
+	}
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			return a.listCars(r)
+		case http.MethodPost:
+			return a.createCar(r)
+		default:
+			return nil, errorf(http.StatusMethodNotAllowed, "method %s is not allowed", r.Method)
+		}
+	}
+
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || id == 0 {
+		return nil, errorf(http.StatusBadRequest, "car id %q is not a positive number", parts[1])
+	}
+	switch r.Method {
+	case http.MethodGet:
+		return a.getCar(id)
+	case http.MethodPut:
+		return a.updateCar(r, id)
+	case http.MethodDelete:
+		return a.deleteCar(id)
+	default:
+		return nil, errorf(http.StatusMethodNotAllowed, "method %s is not allowed", r.Method)
+	}
+}
+
+func (a *API) write(w http.ResponseWriter, body interface{}, apiErr *apiError) {
+	status := http.StatusOK
+	switch b := body.(type) {
+	case nil:
+		status = http.StatusNoContent
+	case created:
+		status, body = http.StatusCreated, b.value
+	}
+	if apiErr != nil {
+		status, body = apiErr.Status, apiErr
+	}
+	requestMetrics.Add(strconv.Itoa(status), 1)
+
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("admin: error writing response - %v", err)
+	}
+}
+
+// serviceError hides the details of internal failures, they are only logged.
+func serviceError(err error) *apiError {
+	switch {
+	case errors.Is(err, carService.ErrNotFound):
+		return errorf(http.StatusNotFound, "car not found")
+	case errors.Is(err, carService.ErrConflict):
+		return errorf(http.StatusConflict, "car has been changed, get it and retry over its current version")
+	case errors.Is(err, carService.ErrUnavailable):
+		return errorf(http.StatusServiceUnavailable, "car service is unavailable, retry later")
+	default:
+		log.Printf("admin: car service failed - %v", err)
+		return errorf(http.StatusInternalServerError, "internal error")
+	}
+}
+
+// CarList is a page of cars, NextCursor is omitted on the last page.
+type CarList struct {
+	Items      []insurance.Car `json:"items"`
+	NextCursor *uint64         `json:"next_cursor,omitempty"`
+}
+
+// carFilter keeps the cars matching all the given conditions.
+type carFilter struct {
+	make  string
+	year  int
+	title string
+}
+
+func (f carFilter) empty() bool {
+	return f == carFilter{}
+}
+
+func (f carFilter) match(car insurance.Car) bool {
+	return (f.make == "" || strings.EqualFold(car.Make, f.make)) &&
+		(f.year == 0 || car.Year == f.year) &&
+		(f.title == "" || strings.Contains(strings.ToLower(car.Title), f.title))
+}
+
+func (a *API) listCars(r *http.Request) (interface{}, *apiError) {
+	query := r.URL.Query()
+
+	cursor, err := uintParam(query.Get("cursor"), 0)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "cursor: %v", err)
+	}
+	limit, err := uintParam(query.Get("limit"), defaultLimit)
+	if err != nil || limit == 0 || limit > maxLimit {
+		return nil, errorf(http.StatusBadRequest, "limit must be a number from 1 to %d", maxLimit)
+	}
+	order := carService.OrderByID
+	if name := query.Get("order"); name != "" {
+		order = carService.ParseOrder(name)
+		if string(order) != name {
+			return nil, errorf(http.StatusBadRequest, "order must be one of %v", carService.Orders)
+		}
+	}
+	filter := carFilter{
+		make:  query.Get("make"),
+		title: strings.ToLower(query.Get("title")),
+	}
+	if year := query.Get("year"); year != "" {
+		if filter.year, err = strconv.Atoi(year); err != nil {
+			return nil, errorf(http.StatusBadRequest, "year must be a number")
+		}
+	}
+
+	// one more car than requested tells whether there is a next page
+	var cars []insurance.Car
+	if filter.empty() {
+		cars, err = a.cars.ListOrdered(order, cursor, limit+1)
+	} else {
+		cars, err = a.scan(order, filter, cursor, limit+1)
+	}
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	list := CarList{Items: cars}
+	if uint64(len(cars)) > limit {
+		next := cursor + limit
+		list.Items, list.NextCursor = cars[:limit], &next
+	}
+
+	return list, nil
+}
+
+// scan reads all cars in the order and returns a page of the matching ones, the cursor counts matching cars.
+func (a *API) scan(order carService.Order, filter carFilter, cursor, limit uint64) ([]insurance.Car, error) {
+	matched := []insurance.Car{}
+	var skipped uint64
+	for offset := uint64(0); ; offset += scanPageSize {
+		cars, err := a.cars.ListOrdered(order, offset, scanPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, car := range cars {
+			if !filter.match(car) {
+				continue
+			}
+			if skipped < cursor {
+				skipped++
+				continue
+			}
+			matched = append(matched, car)
+			if uint64(len(matched)) == limit {
+				return matched, nil
+			}
+		}
+		if len(cars) < scanPageSize {
+			return matched, nil
+		}
+	}
+}
+
+func (a *API) getCar(id uint64) (interface{}, *apiError) {
+	car, err := a.cars.Describe(id)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return car, nil
+}
+
+// CarInput is the editable part of a car, the saved quote is kept by updates.
+// Version is the version the update is made over, without it the update is made over the current one.
+type CarInput struct {
+	Title   string `json:"title"`
+	Make    string `json:"make"`
+	Year    int    `json:"year"`
+	Version uint64 `json:"version,omitempty"`
+}
+
+func decodeInput(r *http.Request) (CarInput, *apiError) {
+	var input CarInput
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		return input, errorf(http.StatusBadRequest, "malformed car: %v", err)
+	}
+
+	input.Title = strings.TrimSpace(input.Title)
+	input.Make = strings.TrimSpace(input.Make)
+	switch {
+	case input.Title == "":
+		return input, errorf(http.StatusBadRequest, "title is required")
+	case input.Year != 0 && (input.Year < 1900 || input.Year > 2100):
+		return input, errorf(http.StatusBadRequest, "year must be from 1900 to 2100")
+	}
+
+	return input, nil
+}
+
+func (a *API) createCar(r *http.Request) (interface{}, *apiError) {
+	input, apiErr := decodeInput(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	id, err := a.cars.Create(insurance.Car{Title: input.Title, Make: input.Make, Year: input.Year})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	car, apiErr := a.getCar(id)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return created{value: car}, nil
+}
+
+func (a *API) updateCar(r *http.Request, id uint64) (interface{}, *apiError) {
+	input, apiErr := decodeInput(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	car, err := a.cars.Describe(id)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	car.Title, car.Make, car.Year = input.Title, input.Make, input.Year
+	if input.Version != 0 {
+		car.Version = input.Version
+	}
+	if err := a.cars.Update(id, *car); err != nil {
+		return nil, serviceError(err)
+	}
+
+	return a.getCar(id)
+}
+
+func (a *API) deleteCar(id uint64) (interface{}, *apiError) {
+	if _, err := a.cars.Remove(id); err != nil {
+		return nil, serviceError(err)
+	}
+
+	return nil, nil
+}
+
+func uintParam(value string, fallback uint64) (uint64, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	return strconv.ParseUint(value, 10, 64)
+}
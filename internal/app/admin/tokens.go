@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Access is what a token allows.
+type Access int
+
+const (
+	AccessNone Access = iota
+	// AccessRead allows only GET requests.
+	AccessRead
+	AccessWrite
+)
+
+// Tokens maps API tokens to their access.
+type Tokens struct {
+	// digests are compared in constant time, so the tokens cannot be guessed by timing
+	digests map[[sha256.Size]byte]Access
+}
+
+func NewTokens() *Tokens {
+	return &Tokens{digests: make(map[[sha256.Size]byte]Access)}
+}
+
+// Add grants the access to a comma separated list of tokens, empty items are skipped.
+func (t *Tokens) Add(access Access, list string) error {
+	for _, token := range strings.Split(list, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		if len(token) < minTokenLength {
+			return fmt.Errorf("a token is shorter than %d characters", minTokenLength)
+		}
+		t.digests[sha256.Sum256([]byte(token))] = access
+	}
+
+	return nil
+}
+
+// minTokenLength keeps guessable tokens out of the configuration.
+const minTokenLength = 16
+
+func (t *Tokens) Empty() bool {
+	return len(t.digests) == 0
+}
+
+// Access checks the bearer token of the request.
+func (t *Tokens) Access(r *http.Request) Access {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return AccessNone
+	}
+	token := strings.TrimPrefix(header, "Bearer ")
+
+	digest := sha256.Sum256([]byte(token))
+	access := AccessNone
+	for known, granted := range t.digests {
+		if subtle.ConstantTimeCompare(known[:], digest[:]) == 1 {
+			access = granted
+		}
+	}
+
+	return access
+}
@@ -0,0 +1,358 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+)
+
+// DateLayout is the format of time.Time arguments.
+const DateLayout = "2006-01-02"
+
+// field is a struct field described with a tag like
+//
+//	`arg:"name,positional,required,default=3,min=1,max=50"`
+//
+// Options:
+//   - positional: the value may be given without "name=", positional fields are filled in order;
+//   - rest: the last positional field takes all remaining words;
+//   - required: the argument must be given;
+//   - nonempty: strings must not be blank;
+//   - default=v: the value used when the argument is absent;
+//   - min=n, max=n: bounds of numbers and of string lengths;
+//   - oneof=a|b: allowed values.
+//
+// Slices are given as comma separated lists, time.Time as 2006-01-02.
+type field struct {
+	index      int
+	name       string
+	positional bool
+	rest       bool
+	required   bool
+	nonempty   bool
+	def        *string
+	min, max   *float64
+	oneof      []string
+}
+
+func fields(t reflect.Type) ([]field, error) {
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		tag, found := t.Field(i).Tag.Lookup("arg")
+		if !found {
+			continue
+		}
+
+		options := strings.Split(tag, ",")
+		f := field{index: i, name: options[0]}
+		for _, option := range options[1:] {
+			key, value := option, ""
+			if eq := strings.IndexByte(option, '='); eq >= 0 {
+				key, value = option[:eq], option[eq+1:]
+			}
+
+			switch key {
+			case "positional":
+				f.positional = true
+			case "rest":
+				f.positional, f.rest = true, true
+			case "required":
+				f.required = true
+			case "nonempty":
+				f.nonempty = true
+			case "default":
+				v := value
+				f.def = &v
+			case "min", "max":
+				bound, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: malformed %s", t.Field(i).Name, key)
+				}
+				if key == "min" {
+					f.min = &bound
+				} else {
+					f.max = &bound
+				}
+			case "oneof":
+				f.oneof = strings.Split(value, "|")
+			default:
+				return nil, fmt.Errorf("field %s: unknown option %q", t.Field(i).Name, key)
+			}
+		}
+		result = append(result, f)
+	}
+
+	return result, nil
+}
+
+// Error describes what is wrong with the arguments.
+type Error struct {
+	Problem i18n.Message
+}
+
+func (e *Error) Error() string {
+	return e.Problem.String()
+}
+
+func problem(key string, args ...interface{}) error {
+	return &Error{Problem: i18n.M(key, args...)}
+}
+
+// Parse fills the struct pointed by dst from the arguments.
+// Fields which are not mentioned keep their values, so dst may be prefilled with dynamic defaults.
+func Parse(input string, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic("args.Parse: dst must be a pointer to struct")
+	}
+	v = v.Elem()
+
+	specs, err := fields(v.Type())
+	if err != nil {
+		panic("args.Parse: " + err.Error())
+	}
+
+	tokens, err := Tokenize(input)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]field, len(specs))
+	var positional []field
+	for _, f := range specs {
+		byName[f.name] = f
+		if f.positional {
+			positional = append(positional, f)
+		}
+	}
+
+	given := make(map[string]string)
+	var loose []string
+	for _, token := range tokens {
+		if eq := strings.IndexByte(token, '='); eq > 0 {
+			if f, found := byName[token[:eq]]; found {
+				if _, duplicate := given[f.name]; duplicate {
+					return problem("args.given_twice", f.name)
+				}
+				given[f.name] = token[eq+1:]
+				continue
+			}
+		}
+		loose = append(loose, token)
+	}
+
+	for _, f := range positional {
+		if len(loose) == 0 {
+			break
+		}
+		if _, found := given[f.name]; found {
+			continue
+		}
+		if f.rest {
+			given[f.name] = strings.Join(loose, " ")
+			loose = nil
+			break
+		}
+		given[f.name], loose = loose[0], loose[1:]
+	}
+	if len(loose) > 0 {
+		return problem("args.unexpected", strings.Join(loose, " "))
+	}
+
+	for _, f := range specs {
+		raw, found := given[f.name]
+		if !found && f.def != nil {
+			raw, found = *f.def, true
+		}
+		if !found {
+			if f.required {
+				return problem("args.required", f.name)
+			}
+			continue
+		}
+
+		if err := f.set(v.Field(f.index), raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f field) set(v reflect.Value, raw string) error {
+	if v.Kind() == reflect.Slice {
+		var parts []string
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if f.required && len(parts) == 0 {
+			return problem("args.empty_list", f.name)
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := f.setScalar(slice.Index(i), part); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+
+		return nil
+	}
+
+	return f.setScalar(v, raw)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (f field) setScalar(v reflect.Value, raw string) error {
+	if len(f.oneof) > 0 && !contains(f.oneof, raw) {
+		return problem("args.one_of", f.name, strings.Join(f.oneof, ", "))
+	}
+
+	if v.Type() == timeType {
+		parsed, err := time.Parse(DateLayout, raw)
+		if err != nil {
+			return problem("args.date", f.name, DateLayout)
+		}
+		v.Set(reflect.ValueOf(parsed))
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if f.nonempty && strings.TrimSpace(raw) == "" {
+			return problem("args.empty", f.name)
+		}
+		if err := f.check(float64(len([]rune(raw))), i18n.M("args.length_of", f.name)); err != nil {
+			return err
+		}
+		v.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return problem("args.integer", f.name)
+		}
+		if err := f.check(float64(parsed), f.name); err != nil {
+			return err
+		}
+		v.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return problem("args.unsigned", f.name)
+		}
+		if err := f.check(float64(parsed), f.name); err != nil {
+			return err
+		}
+		v.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return problem("args.number", f.name)
+		}
+		if err := f.check(parsed, f.name); err != nil {
+			return err
+		}
+		v.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return problem("args.bool", f.name)
+		}
+		v.SetBool(parsed)
+	default:
+		panic(fmt.Sprintf("args: unsupported type %s of %s", v.Type(), f.name))
+	}
+
+	return nil
+}
+
+// check validates the bounds, what names the checked value and may be a translatable message.
+func (f field) check(value float64, what interface{}) error {
+	if f.min != nil && value < *f.min {
+		return problem("args.min", what, *f.min)
+	}
+	if f.max != nil && value > *f.max {
+		return problem("args.max", what, *f.max)
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Usage describes the arguments of the struct, e.g. "<id> [title] [year=...]".
+func Usage(dst interface{}) string {
+	t := reflect.TypeOf(dst)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	specs, err := fields(t)
+	if err != nil {
+		panic("args.Usage: " + err.Error())
+	}
+
+	parts := make([]string, 0, len(specs))
+	for _, f := range specs {
+		name := f.name
+		switch {
+		case f.rest:
+			name += "..."
+		case !f.positional && len(f.oneof) > 0:
+			name += "=" + strings.Join(f.oneof, "|")
+		case !f.positional:
+			name += "=..."
+		case len(f.oneof) > 0:
+			name = strings.Join(f.oneof, "|")
+		}
+
+		if f.required {
+			parts = append(parts, "<"+name+">")
+		} else {
+			parts = append(parts, "["+name+"]")
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// ParseMessage parses the arguments of the command message,
+// failures are reported as bad arguments with the usage of the command.
+func ParseMessage(msg *tgbotapi.Message, dst interface{}) error {
+	err := Parse(msg.CommandArguments(), dst)
+	if err == nil {
+		return nil
+	}
+
+	usage := "/" + msg.Command()
+	if described := Usage(dst); described != "" {
+		usage += " " + described
+	}
+
+	var argsErr *Error
+	if !errors.As(err, &argsErr) {
+		return cmderr.Internal(err)
+	}
+
+	return cmderr.InvalidArguments(argsErr.Problem, usage)
+}
@@ -0,0 +1,102 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testArgs struct {
+	ID       uint64    `arg:"id,positional,required"`
+	Count    int       `arg:"count,positional,default=3,min=1,max=50"`
+	Title    string    `arg:"title,rest,nonempty,max=12"`
+	Coverage string    `arg:"coverage,oneof=liability|collision"`
+	Rate     float64   `arg:"rate,min=0"`
+	Tags     []string  `arg:"tags"`
+	Since    time.Time `arg:"since"`
+	Active   bool      `arg:"active"`
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    testArgs
+		problem string
+	}{
+		{input: "7", want: testArgs{ID: 7, Count: 3}},
+		{input: "7 10 Nissan Leaf", want: testArgs{ID: 7, Count: 10, Title: "Nissan Leaf"}},
+		{input: "id=7 title=Leaf count=5", want: testArgs{ID: 7, Count: 5, Title: "Leaf"}},
+		{input: "7 count=5 Ford's car", want: testArgs{ID: 7, Count: 5, Title: "Ford's car"}},
+		{
+			input: "7 coverage=collision rate=1.5 tags=a,,b since=2021-03-04 active=true",
+			want: testArgs{
+				ID: 7, Count: 3, Coverage: "collision", Rate: 1.5, Tags: []string{"a", "b"},
+				Since: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), Active: true,
+			},
+		},
+		{input: "", problem: "args.required"},
+		{input: "count=5", problem: "args.required"},
+		{input: "x", problem: "args.unsigned"},
+		{input: "-1", problem: "args.unsigned"},
+		{input: "7 0", problem: "args.min"},
+		{input: "7 51", problem: "args.max"},
+		{input: "7 3 title=Mitsubishi_Lancer_Evo", problem: "args.max"},
+		{input: `7 3 title=" "`, problem: "args.empty"},
+		{input: "7 coverage=full", problem: "args.one_of"},
+		{input: "7 rate=-1", problem: "args.min"},
+		{input: "7 rate=x", problem: "args.number"},
+		{input: "7 tags=,", want: testArgs{ID: 7, Count: 3, Tags: []string{}}},
+		{input: "7 since=04.03.2021", problem: "args.date"},
+		{input: "7 active=maybe", problem: "args.bool"},
+		{input: "id=7 title=a title=b", problem: "args.given_twice"},
+	}
+
+	for _, tt := range tests {
+		var got testArgs
+		err := Parse(tt.input, &got)
+		if tt.problem != "" {
+			var argsErr *Error
+			if !errors.As(err, &argsErr) || argsErr.Problem.Key != tt.problem {
+				t.Errorf("Parse(%q) error = %v, want %s", tt.input, err, tt.problem)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+type keyArgs struct {
+	ID   uint64 `arg:"id,positional,required"`
+	Make string `arg:"make"`
+}
+
+func TestParseUnknownKey(t *testing.T) {
+	var got keyArgs
+	for _, input := range []string{"7 color=red", "7 8"} {
+		err := Parse(input, &got)
+
+		var argsErr *Error
+		if !errors.As(err, &argsErr) || argsErr.Problem.Key != "args.unexpected" {
+			t.Errorf("Parse(%q) error = %v, want args.unexpected", input, err)
+		}
+	}
+}
+
+func TestParseKeepsPrefilledValues(t *testing.T) {
+	got := keyArgs{Make: "Toyota"}
+	if err := Parse("7", &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Make != "Toyota" {
+		t.Errorf("omitted argument changed the prefilled value to %q", got.Make)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	if got, want := Usage(testArgs{}), "<id> [count] [title...] [coverage=liability|collision] [rate=...] [tags=...] [since=...] [active=...]"; got != want {
+		t.Errorf("Usage() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package args
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits input by spaces, single or double quotes group words and backslash escapes a character.
+// A quote opens only at the start of a word or of its value after "name=", so apostrophes like in "Ford's" are kept.
+func Tokenize(input string) ([]string, error) {
+	var (
+		tokens  []string
+		current strings.Builder
+		inToken bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range input {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped, inToken = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case (r == '"' || r == '\'') && (!inToken || valueStart(current.String())):
+			quote, inToken = r, true
+		case unicode.IsSpace(r):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, problem("args.quote", string(quote))
+	}
+	if escaped {
+		current.WriteRune('\\')
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
+
+// valueStart tells whether the word so far is a name followed by "=", e.g. title="Nissan Leaf".
+func valueStart(word string) bool {
+	return strings.HasSuffix(word, "=") && strings.IndexByte(word, '=') == len(word)-1
+}
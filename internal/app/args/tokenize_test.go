@@ -0,0 +1,44 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		problem string
+	}{
+		{input: "", want: nil},
+		{input: "  Toyota   Corolla ", want: []string{"Toyota", "Corolla"}},
+		{input: "Ford's car", want: []string{"Ford's", "car"}},
+		{input: "O'Neil's car", want: []string{"O'Neil's", "car"}},
+		{input: `"Nissan Leaf" 2015`, want: []string{"Nissan Leaf", "2015"}},
+		{input: `'it"s' x`, want: []string{`it"s`, "x"}},
+		{input: `title="Nissan Leaf" year=2015`, want: []string{"title=Nissan Leaf", "year=2015"}},
+		{input: `a=b="c d"`, want: []string{`a=b="c`, `d"`}},
+		{input: `Nissan\ Leaf`, want: []string{"Nissan Leaf"}},
+		{input: `say \"hi\"`, want: []string{"say", `"hi"`}},
+		{input: `trailing\`, want: []string{`trailing\`}},
+		{input: `""`, want: []string{""}},
+		{input: `"Nissan Leaf`, problem: "args.quote"},
+		{input: `title='Nissan`, problem: "args.quote"},
+	}
+
+	for _, tt := range tests {
+		got, err := Tokenize(tt.input)
+		if tt.problem != "" {
+			var argsErr *Error
+			if !errors.As(err, &argsErr) || argsErr.Problem.Key != tt.problem {
+				t.Errorf("Tokenize(%q) error = %v, want %s", tt.input, err, tt.problem)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/middleware"
+)
+
+// RequiredRole returns the role needed for the command, commands without metadata are not restricted.
+type RequiredRole func(command string) (Role, bool)
+
+// Middleware rejects commands and callbacks the user has no role for.
+func Middleware(resolver Resolver, required RequiredRole) middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return middleware.HandlerFunc(func(update tgbotapi.Update) error {
+			command, userID, _, ok := middleware.Source(update)
+			if !ok || command == "" {
+				return next.HandleUpdate(update)
+			}
+
+			role, found := required(command)
+			if found && resolver.Role(userID) < role {
+				return cmderr.Forbidden("auth.requires_role", "/"+command, i18n.M("role."+role.String()))
+			}
+
+			return next.HandleUpdate(update)
+		})
+	}
+}
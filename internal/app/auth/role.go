@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Role grants access to commands, every role includes the lower ones.
+type Role int
+
+const (
+	RoleUser Role = iota
+	RoleAgent
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleAgent:
+		return "agent"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "user"
+	}
+}
+
+func ParseRole(s string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "user":
+		return RoleUser, nil
+	case "agent":
+		return RoleAgent, nil
+	case "admin":
+		return RoleAdmin, nil
+	default:
+		return RoleUser, fmt.Errorf("unknown role %q", s)
+	}
+}
+
+type Resolver interface {
+	Role(userID int64) Role
+}
+
+// StaticResolver assigns roles from fixed lists of user IDs.
+type StaticResolver struct {
+	Default Role
+	roles   map[int64]Role
+}
+
+func NewStaticResolver(defaultRole Role) *StaticResolver {
+	return &StaticResolver{Default: defaultRole, roles: make(map[int64]Role)}
+}
+
+// Grant assigns the role to comma separated user IDs.
+func (r *StaticResolver) Grant(role Role, userIDs string) error {
+	for _, id := range strings.Split(userIDs, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+
+		userID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return fmt.Errorf("malformed user id %q", id)
+		}
+		r.roles[userID] = role
+	}
+
+	return nil
+}
+
+func (r *StaticResolver) Role(userID int64) Role {
+	if role, found := r.roles[userID]; found {
+		return role
+	}
+
+	return r.Default
+}
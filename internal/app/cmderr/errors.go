@@ -0,0 +1,133 @@
+package cmderr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+)
+
+// Kind classifies command errors by the way they are shown to the user.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindUnknownCommand
+	KindBadArguments
+	KindNotFound
+	KindForbidden
+	KindRateLimited
+	KindConflict
+	KindUnavailable
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindUnknownCommand:
+		return "unknown_command"
+	case KindBadArguments:
+		return "bad_arguments"
+	case KindNotFound:
+		return "not_found"
+	case KindForbidden:
+		return "forbidden"
+	case KindRateLimited:
+		return "rate_limited"
+	case KindConflict:
+		return "conflict"
+	case KindUnavailable:
+		return "unavailable"
+	default:
+		return "internal"
+	}
+}
+
+// Error is returned by commanders, the router renders it as a reply.
+type Error struct {
+	Kind Kind
+	// Detail is the command or the usage string the error is about, it may be empty.
+	Detail string
+	// Message is a user-facing clarification translated to the language of the user,
+	// for bad arguments it explains what is wrong with them.
+	Message i18n.Message
+	// Err is the cause, it is logged but never shown to the user.
+	Err error
+}
+
+func (e *Error) Error() string {
+	detail := e.Detail
+	if e.Message.Key != "" {
+		detail = e.Message.String()
+	}
+
+	switch {
+	case detail != "" && e.Err != nil:
+		return fmt.Sprintf("%s: %s: %v", e.Kind, detail, e.Err)
+	case e.Err != nil:
+		return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+	case detail != "":
+		return fmt.Sprintf("%s: %s", e.Kind, detail)
+	default:
+		return e.Kind.String()
+	}
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func UnknownCommand(command string) error {
+	return &Error{Kind: KindUnknownCommand, Detail: command}
+}
+
+// BadArguments carries the usage string of the command.
+func BadArguments(usage string) error {
+	return &Error{Kind: KindBadArguments, Detail: usage}
+}
+
+// InvalidArguments tells what is wrong along with the usage of the command.
+func InvalidArguments(problem i18n.Message, usage string) error {
+	return &Error{Kind: KindBadArguments, Detail: usage, Message: problem}
+}
+
+// NotFound describes the missing entity with the catalog message key.
+func NotFound(cause error, key string, args ...interface{}) error {
+	return &Error{Kind: KindNotFound, Message: i18n.M(key, args...), Err: cause}
+}
+
+func Forbidden(key string, args ...interface{}) error {
+	return &Error{Kind: KindForbidden, Message: i18n.M(key, args...)}
+}
+
+// RateLimited carries the number of seconds after which the user may retry.
+func RateLimited(retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+
+	return &Error{Kind: KindRateLimited, Message: i18n.M("error.rate_limited", seconds)}
+}
+
+// Conflict tells that the change does not fit the current state of the entity.
+func Conflict(cause error, key string, args ...interface{}) error {
+	return &Error{Kind: KindConflict, Message: i18n.M(key, args...), Err: cause}
+}
+
+// Unavailable tells that a backend cannot be reached right now and the user may retry later.
+func Unavailable(cause error) error {
+	return &Error{Kind: KindUnavailable, Err: cause}
+}
+
+func Internal(cause error) error {
+	return &Error{Kind: KindInternal, Err: cause}
+}
+
+// As extracts *Error from the chain, untyped errors are treated as internal ones.
+func As(err error) *Error {
+	var cmdErr *Error
+	if errors.As(err, &cmdErr) {
+		return cmdErr
+	}
+
+	return &Error{Kind: KindInternal, Err: err}
+}
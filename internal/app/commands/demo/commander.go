@@ -4,22 +4,24 @@ import (
 	"log"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
 	"github.com/ozonmp/omp-bot/internal/app/commands/demo/subdomain"
 	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
 )
 
 type Commander interface {
-	HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath)
-	HandleCommand(message *tgbotapi.Message, commandPath path.CommandPath)
+	HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error
+	HandleCommand(message *tgbotapi.Message, commandPath path.CommandPath) error
 }
 
 type DemoCommander struct {
-	bot                *tgbotapi.BotAPI
+	bot                sender.Sender
 	subdomainCommander Commander
 }
 
 func NewDemoCommander(
-	bot *tgbotapi.BotAPI,
+	bot sender.Sender,
 ) *DemoCommander {
 	return &DemoCommander{
 		bot: bot,
@@ -28,20 +30,22 @@ func NewDemoCommander(
 	}
 }
 
-func (c *DemoCommander) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) {
+func (c *DemoCommander) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
 	switch callbackPath.Subdomain {
 	case "subdomain":
-		c.subdomainCommander.HandleCallback(callback, callbackPath)
+		return c.subdomainCommander.HandleCallback(callback, callbackPath)
 	default:
 		log.Printf("DemoCommander.HandleCallback: unknown subdomain - %s", callbackPath.Subdomain)
+		return cmderr.UnknownCommand(callbackPath.String())
 	}
 }
 
-func (c *DemoCommander) HandleCommand(msg *tgbotapi.Message, commandPath path.CommandPath) {
+func (c *DemoCommander) HandleCommand(msg *tgbotapi.Message, commandPath path.CommandPath) error {
 	switch commandPath.Subdomain {
 	case "subdomain":
-		c.subdomainCommander.HandleCommand(msg, commandPath)
+		return c.subdomainCommander.HandleCommand(msg, commandPath)
 	default:
 		log.Printf("DemoCommander.HandleCommand: unknown subdomain - %s", commandPath.Subdomain)
+		return cmderr.UnknownCommand(commandPath.String())
 	}
 }
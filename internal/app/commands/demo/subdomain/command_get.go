@@ -5,21 +5,20 @@ import (
 	"strconv"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
 )
 
-func (c *DemoSubdomainCommander) Get(inputMessage *tgbotapi.Message) {
+func (c *DemoSubdomainCommander) Get(inputMessage *tgbotapi.Message) error {
 	args := inputMessage.CommandArguments()
 
 	idx, err := strconv.Atoi(args)
 	if err != nil {
-		log.Println("wrong args", args)
-		return
+		return cmderr.BadArguments("/get__demo__subdomain <index>")
 	}
 
 	product, err := c.subdomainService.Get(idx)
 	if err != nil {
-		log.Printf("fail to get product with idx %d: %v", idx, err)
-		return
+		return cmderr.NotFound(err, "demo.not_found", idx)
 	}
 
 	msg := tgbotapi.NewMessage(
@@ -31,4 +30,6 @@ func (c *DemoSubdomainCommander) Get(inputMessage *tgbotapi.Message) {
 	if err != nil {
 		log.Printf("DemoSubdomainCommander.Get: error sending reply message to chat - %v", err)
 	}
+
+	return nil
 }
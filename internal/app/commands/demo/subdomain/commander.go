@@ -4,17 +4,19 @@ import (
 	"log"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
 	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
 	"github.com/ozonmp/omp-bot/internal/service/demo/subdomain"
 )
 
 type DemoSubdomainCommander struct {
-	bot              *tgbotapi.BotAPI
+	bot              sender.Sender
 	subdomainService *subdomain.Service
 }
 
 func NewDemoSubdomainCommander(
-	bot *tgbotapi.BotAPI,
+	bot sender.Sender,
 ) *DemoSubdomainCommander {
 	subdomainService := subdomain.NewService()
 
@@ -24,24 +26,28 @@ func NewDemoSubdomainCommander(
 	}
 }
 
-func (c *DemoSubdomainCommander) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) {
+func (c *DemoSubdomainCommander) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
 	switch callbackPath.CallbackName {
 	case "list":
 		c.CallbackList(callback, callbackPath)
+		return nil
 	default:
 		log.Printf("DemoSubdomainCommander.HandleCallback: unknown callback name: %s", callbackPath.CallbackName)
+		return cmderr.UnknownCommand(callbackPath.String())
 	}
 }
 
-func (c *DemoSubdomainCommander) HandleCommand(msg *tgbotapi.Message, commandPath path.CommandPath) {
+func (c *DemoSubdomainCommander) HandleCommand(msg *tgbotapi.Message, commandPath path.CommandPath) error {
 	switch commandPath.CommandName {
 	case "help":
 		c.Help(msg)
 	case "list":
 		c.List(msg)
 	case "get":
-		c.Get(msg)
+		return c.Get(msg)
 	default:
 		c.Default(msg)
 	}
+
+	return nil
 }
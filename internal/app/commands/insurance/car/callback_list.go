@@ -2,10 +2,13 @@ package car
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
 	"github.com/ozonmp/omp-bot/internal/app/path"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
 )
 
 type CallbackListData struct {
@@ -13,26 +16,33 @@ type CallbackListData struct {
 	PageSize int `json:"page_size"`
 }
 
-func (c *CarCommanderImpl) CallbackList(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) {
+func (c *CarCommanderImpl) CallbackList(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
 	parsedData := CallbackListData{}
 	err := json.Unmarshal([]byte(callbackPath.CallbackData), &parsedData)
 	if err != nil {
-		log.Printf("CarCommanderImpl.CallbackList: "+
-			"error reading json data for type CallbackListData from "+
-			"input string %v - %v", callbackPath.CallbackData, err)
-		return
+		return cmderr.Internal(err)
+	}
+	if parsedData.Offset < 0 || parsedData.PageSize <= 0 {
+		return cmderr.Internal(fmt.Errorf("list page is out of range: %+v", parsedData))
+	}
+	settings, err := c.userSettings(callback.From)
+	if err != nil {
+		return err
 	}
 	msg, err := c.listPage(
 		callback.Message.Chat.ID,
 		uint64(parsedData.Offset),
 		uint64(parsedData.PageSize),
+		carService.ParseOrder(settings.Sort),
+		c.localizer.For(callback.From),
 	)
 	if err != nil {
-		log.Printf("cannot make paged list: %v\n", err)
-		return
+		return err
 	}
 	_, err = c.bot.Send(msg)
 	if err != nil {
 		log.Printf("CarCommanderImpl.CallbackList: error sending reply message to chat - %v", err)
 	}
+
+	return nil
 }
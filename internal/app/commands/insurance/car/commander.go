@@ -2,85 +2,112 @@ package car
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/args"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
 	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/render"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+	userSettings "github.com/ozonmp/omp-bot/internal/app/settings"
+	"github.com/ozonmp/omp-bot/internal/app/watch"
 	"github.com/ozonmp/omp-bot/internal/model/insurance"
 	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+	"github.com/ozonmp/omp-bot/internal/service/insurance/rating"
 	"log"
-	"strconv"
-	"strings"
 )
 
 type CarCommander interface {
-	Help(inputMsg *tgbotapi.Message)
-	Get(inputMsg *tgbotapi.Message)
-	List(inputMsg *tgbotapi.Message)
-	Delete(inputMsg *tgbotapi.Message)
-
-	New(inputMsg *tgbotapi.Message)
-	Edit(inputMsg *tgbotapi.Message)
+	Help(inputMsg *tgbotapi.Message) error
+	Get(inputMsg *tgbotapi.Message) error
+	List(inputMsg *tgbotapi.Message) error
+	Delete(inputMsg *tgbotapi.Message) error
+
+	New(inputMsg *tgbotapi.Message) error
+	Edit(inputMsg *tgbotapi.Message) error
+	Quote(inputMsg *tgbotapi.Message) error
+	Watch(inputMsg *tgbotapi.Message) error
+	Unwatch(inputMsg *tgbotapi.Message) error
 }
 
 type CarCommanderImpl struct {
-	bot             *tgbotapi.BotAPI
-	service         carService.CarService
-	defaultPageSize uint64
-}
-
-func (c *CarCommanderImpl) Help(inputMsg *tgbotapi.Message) {
-	msg := tgbotapi.NewMessage(inputMsg.Chat.ID,
-		"/help__insurance__car — print list of commands\n"+
-			"/get__insurance__car — get an entity\n"+
-			"/list__insurance__car — get a list of your entity\n"+
-			"/delete__insurance__car — delete an existing entity\n"+
-			"/new__insurance__car — create a new entity\n"+
-			"/edit__insurance__car — edit an entity",
-	)
+	bot       sender.Sender
+	service   carService.CarService
+	localizer *i18n.Localizer
+	renderer  *render.Renderer
+	settings  *userSettings.Store
+	engine    rating.Engine
+	quotes    *pendingCache
+	edits     *pendingCache
+	watches   *watch.Store
+}
+
+func (c *CarCommanderImpl) Help(inputMsg *tgbotapi.Message) error {
+	msg := tgbotapi.NewMessage(inputMsg.Chat.ID, meta.Help(commands, c.localizer.For(inputMsg.From)))
 
 	_, err := c.bot.Send(msg)
 	if err != nil {
 		log.Printf("InsuranceCarCommander.Help: error sending reply message to chat - %v", err)
 	}
+
+	return nil
 }
 
-func (c *CarCommanderImpl) Get(inputMsg *tgbotapi.Message) {
-	args := inputMsg.CommandArguments()
+func (c *CarCommanderImpl) Get(inputMsg *tgbotapi.Message) error {
+	var parsed idArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	car, err := c.service.Describe(parsed.ID)
+	if err != nil {
+		return serviceError(err, parsed.ID)
+	}
 
-	idx, err := strconv.ParseUint(args, 10, 0)
+	p := c.localizer.For(inputMsg.From)
+	msg, err := c.renderer.Message(inputMsg.Chat.ID, "card", p, car)
 	if err != nil {
-		msg := "Wrong args! Should be id of the car to get"
-		log.Println(msg, args)
-		c.sendMessageToUser(inputMsg.Chat.ID, msg)
-		return
+		return cmderr.Internal(err)
 	}
+	// the button keeps the version of this card, so the edit fails if the car is changed meanwhile
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("car.card.edit"), editCallback(*car).String()),
+		),
+	)
 
-	car, err := c.service.Describe(idx)
-	var msgToShow string
+	_, err = c.bot.Send(msg)
 	if err != nil {
-		log.Printf("fail to get car with idx %d: %v", idx, err)
-		msgToShow = fmt.Sprintf("failed to get car with idx %d", idx)
-	} else {
-		msgToShow = car.String()
+		log.Printf("CarCommander.Get: error sending reply message to chat - %v", err)
 	}
 
-	c.sendMessageToUser(inputMsg.Chat.ID, msgToShow)
+	return nil
 }
 
-func (c *CarCommanderImpl) listPage(chatID int64, cursor, pageSize uint64) (*tgbotapi.MessageConfig, error) {
-	cars, err := c.service.List(cursor, pageSize)
+func (c *CarCommanderImpl) listPage(
+	chatID int64,
+	cursor, pageSize uint64,
+	order carService.Order,
+	p i18n.Printer,
+) (*tgbotapi.MessageConfig, error) {
+	cars, err := c.service.ListOrdered(order, cursor, pageSize)
 	if err != nil {
-		return nil, err
+		return nil, serviceError(err, 0)
+	}
+	if len(cars) == 0 {
+		return nil, cmderr.NotFound(nil, "car.list.end")
 	}
 
-	var b strings.Builder
-	b.WriteString("Here is the paged list of the cars: \n\n")
-	for _, c := range cars {
-		b.WriteString(c.String())
-		b.WriteString("\n")
+	msg, err := c.renderer.Message(chatID, "list", p, cars)
+	if err != nil {
+		return nil, cmderr.Internal(err)
 	}
 
-	msg := tgbotapi.NewMessage(chatID, b.String())
+	if uint64(len(cars)) < pageSize {
+		return &msg, nil
+	}
 
 	serializedData, _ := json.Marshal(CallbackListData{
 		Offset:   int(cursor + pageSize),
@@ -96,140 +123,214 @@ func (c *CarCommanderImpl) listPage(chatID int64, cursor, pageSize uint64) (*tgb
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Next page", callbackPath.String()),
+			tgbotapi.NewInlineKeyboardButtonData(p.T("car.list.next"), callbackPath.String()),
 		),
 	)
 	return &msg, nil
 }
 
-func (c *CarCommanderImpl) List(inputMsg *tgbotapi.Message) {
-	pageSize := c.defaultPageSize
-
-	argsString := inputMsg.CommandArguments()
+func (c *CarCommanderImpl) List(inputMsg *tgbotapi.Message) error {
+	settings, err := c.userSettings(inputMsg.From)
+	if err != nil {
+		return err
+	}
 
-	args := strings.Split(argsString, " ")
-	if len(args) == 1 && args[0] != "" {
-		var err error
-		pageSize, err = strconv.ParseUint(args[0], 10, 0)
-		if err != nil {
-			log.Println("wrong args", args)
-			return
-		}
-	} else {
-		log.Printf("list page size not provided, use default = %d", pageSize)
+	parsed := listArgs{PageSize: settings.PageSizeOr(userSettings.DefaultPageSize)}
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
 	}
 
-	msg, err := c.listPage(inputMsg.Chat.ID, 0, pageSize)
+	msg, err := c.listPage(
+		inputMsg.Chat.ID,
+		0,
+		parsed.PageSize,
+		carService.ParseOrder(settings.Sort),
+		c.localizer.For(inputMsg.From),
+	)
 	if err != nil {
-		log.Printf("cannot make paged list: %v\n", err)
-		return
+		return err
 	}
 
 	_, err = c.bot.Send(*msg)
 	if err != nil {
 		log.Printf("CarCommander.List: error sending reply message to chat - %v", err)
 	}
+
+	return nil
 }
 
-func (c *CarCommanderImpl) Delete(inputMsg *tgbotapi.Message) {
-	args := inputMsg.CommandArguments()
+func (c *CarCommanderImpl) Delete(inputMsg *tgbotapi.Message) error {
+	var parsed idArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
 
-	idx, err := strconv.ParseUint(args, 10, 0)
+	_, err := c.service.Remove(parsed.ID)
 	if err != nil {
-		errorMsg := "Wrong args! Should be id of the car to delete"
-		log.Println(errorMsg, args)
-		c.sendMessageToUser(inputMsg.Chat.ID, errorMsg)
-		return
+		return serviceError(err, parsed.ID)
 	}
 
-	var msgToShow string
-	_, err = c.service.Remove(idx)
+	c.sendMessageToUser(inputMsg.Chat.ID, c.localizer.For(inputMsg.From).T("car.deleted", parsed.ID))
+
+	return nil
+}
+
+func (c *CarCommanderImpl) New(inputMsg *tgbotapi.Message) error {
+	var parsed newArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	id, err := c.service.Create(insurance.Car{Title: parsed.Title, Make: parsed.Make, Year: parsed.Year})
 	if err != nil {
-		log.Printf("failed to delete car with idx %d: %v", idx, err)
-		msgToShow = fmt.Sprintf("failed to delete car with idx %d", idx)
-	} else {
-		msgToShow = "deleted successfully"
+		return serviceError(err, 0)
 	}
+	msgToShow := c.localizer.For(inputMsg.From).T("car.added", id)
 
 	c.sendMessageToUser(inputMsg.Chat.ID, msgToShow)
+
+	return nil
 }
 
-func (c *CarCommanderImpl) New(inputMsg *tgbotapi.Message) {
-	argsString := inputMsg.CommandArguments()
-	id, err := c.service.Create(insurance.Car{Title: argsString})
+// Watch subscribes the chat to changes of the car.
+func (c *CarCommanderImpl) Watch(inputMsg *tgbotapi.Message) error {
+	var parsed idArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	if _, err := c.service.Describe(parsed.ID); err != nil {
+		return serviceError(err, parsed.ID)
+	}
+
+	added, err := c.watches.Watch(watch.CarEntity, parsed.ID, inputMsg.Chat.ID)
 	if err != nil {
-		log.Printf("CarCommander.New: error sending reply message to chat - %v", err)
-		return
+		return cmderr.Internal(err)
 	}
-	msgToShow := fmt.Sprintf("Successfully added car with id %d", id)
 
-	c.sendMessageToUser(inputMsg.Chat.ID, msgToShow)
+	key := "car.watch.added"
+	if !added {
+		key = "car.watch.already"
+	}
+	c.sendMessageToUser(inputMsg.Chat.ID, c.localizer.For(inputMsg.From).T(key, parsed.ID))
+
+	return nil
 }
 
-func (c *CarCommanderImpl) Edit(inputMsg *tgbotapi.Message) {
-	argsString := inputMsg.CommandArguments()
-	args := strings.SplitN(argsString, " ", 2)
-	if len(args) != 2 {
-		log.Println("wrong args number, should be 2 but passed:", args)
-		c.sendMessageToUser(inputMsg.Chat.ID, "There are should be two args: car ID and car title!")
-		return
+func (c *CarCommanderImpl) Unwatch(inputMsg *tgbotapi.Message) error {
+	var parsed idArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
 	}
-	var errMsg string
-	carID, err := strconv.ParseUint(args[0], 10, 0)
+
+	removed, err := c.watches.Unwatch(watch.CarEntity, parsed.ID, inputMsg.Chat.ID)
 	if err != nil {
-		errMsg = "wrong carID"
-		log.Println(errMsg, args)
-		c.sendMessageToUser(inputMsg.Chat.ID, errMsg)
-		return
+		return cmderr.Internal(err)
 	}
 
-	errMsg = fmt.Sprintf("Successfully edited car with id %d", carID)
-	err = c.service.Update(carID, insurance.Car{Title: args[1]})
-	if err != nil {
-		log.Printf("CarCommander.Edit:  - %v", err)
-		errMsg = fmt.Sprintf("Failed to edit car with id %d", carID)
+	key := "car.watch.removed"
+	if !removed {
+		key = "car.watch.not_watching"
 	}
-	c.sendMessageToUser(inputMsg.Chat.ID, errMsg)
+	c.sendMessageToUser(inputMsg.Chat.ID, c.localizer.For(inputMsg.From).T(key, parsed.ID))
+
+	return nil
 }
 
 func (c *CarCommanderImpl) sendMessageToUser(chatId int64, msgToShow string) {
 	msg := tgbotapi.NewMessage(
 		chatId,
-		fmt.Sprintf(msgToShow),
+		msgToShow,
 	)
 	_, err := c.bot.Send(msg)
 	if err != nil {
 		log.Printf("CarCommander: error sending reply message to chat - %v", err)
 	}
 }
-func (c CarCommanderImpl) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) {
+
+// userSettings returns the preferences of the user, messages from channels have no user.
+func (c *CarCommanderImpl) userSettings(user *tgbotapi.User) (userSettings.Settings, error) {
+	if user == nil {
+		return userSettings.Settings{}, nil
+	}
+
+	settings, err := c.settings.Get(int64(user.ID))
+	if err != nil {
+		return settings, cmderr.Internal(err)
+	}
+
+	return settings, nil
+}
+
+// serviceError maps service failures for a single car to command errors.
+func serviceError(err error, carID uint64) error {
+	switch {
+	case errors.Is(err, carService.ErrNotFound):
+		return cmderr.NotFound(err, "car.not_found", carID)
+	case errors.Is(err, carService.ErrConflict):
+		return cmderr.Conflict(err, "car.conflict", carID)
+	case errors.Is(err, carService.ErrUnavailable):
+		return cmderr.Unavailable(err)
+	default:
+		return cmderr.Internal(err)
+	}
+}
+
+func (c CarCommanderImpl) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
 	switch callbackPath.CallbackName {
 	case "list":
-		c.CallbackList(callback, callbackPath)
+		return c.CallbackList(callback, callbackPath)
+	case "quote":
+		return c.CallbackQuote(callback, callbackPath)
+	case "edit":
+		return c.CallbackEdit(callback, callbackPath)
 	default:
-		log.Printf("CarCommander.HandleCallback: unknown callback name: %s", callbackPath.CallbackName)
+		return cmderr.UnknownCommand(callbackPath.CallbackName)
 	}
 }
 
-func (c CarCommanderImpl) HandleCommand(message *tgbotapi.Message, commandPath path.CommandPath) {
+func (c CarCommanderImpl) HandleCommand(message *tgbotapi.Message, commandPath path.CommandPath) error {
 	switch commandPath.CommandName {
 	case "help":
-		c.Help(message)
+		return c.Help(message)
 	case "list":
-		c.List(message)
+		return c.List(message)
 	case "get":
-		c.Get(message)
+		return c.Get(message)
 	case "delete":
-		c.Delete(message)
+		return c.Delete(message)
 	case "new":
-		c.New(message)
+		return c.New(message)
 	case "edit":
-		c.Edit(message)
+		return c.Edit(message)
+	case "quote":
+		return c.Quote(message)
+	case "watch":
+		return c.Watch(message)
+	case "unwatch":
+		return c.Unwatch(message)
 	default:
-		panic("There's nothing I can do")
+		return cmderr.UnknownCommand(commandPath.String())
 	}
 }
 
-func NewCarCommander(bot *tgbotapi.BotAPI, service carService.CarService) CarCommanderImpl {
-	return CarCommanderImpl{bot: bot, service: service, defaultPageSize: 3}
+func NewCarCommander(
+	bot sender.Sender,
+	service carService.CarService,
+	localizer *i18n.Localizer,
+	settings *userSettings.Store,
+	engine rating.Engine,
+	watches *watch.Store,
+) CarCommanderImpl {
+	return CarCommanderImpl{
+		bot:       bot,
+		service:   service,
+		localizer: localizer,
+		renderer:  templates,
+		settings:  settings,
+		engine:    engine,
+		quotes:    newPendingCache(pendingQuotes),
+		edits:     newPendingCache(pendingEdits),
+		watches:   watches,
+	}
 }
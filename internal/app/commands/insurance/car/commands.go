@@ -0,0 +1,64 @@
+package car
+
+import (
+	"github.com/ozonmp/omp-bot/internal/app/args"
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+)
+
+type idArgs struct {
+	ID uint64 `arg:"id,positional,required"`
+}
+
+type listArgs struct {
+	PageSize uint64 `arg:"page_size,positional,min=1,max=50"`
+}
+
+type newArgs struct {
+	Title string `arg:"title,rest,required,nonempty,max=100"`
+	Make  string `arg:"make,max=50"`
+	Year  int    `arg:"year,min=1900,max=2100"`
+}
+
+// editArgs are parsed over the values of the edited car, so omitted arguments keep them.
+// Version is the one shown on the card the user edits, the edit button of the card puts it into the command.
+// Without it the edit is made over the current version, like in the admin API.
+type editArgs struct {
+	ID      uint64 `arg:"id,positional,required"`
+	Title   string `arg:"title,rest,nonempty,max=100"`
+	Make    string `arg:"make,max=50"`
+	Year    int    `arg:"year,min=1900,max=2100"`
+	Version uint64 `arg:"version"`
+}
+
+// quoteArgs are the rated attributes, make and year override the ones of the car.
+type quoteArgs struct {
+	ID         uint64  `arg:"id,positional,required"`
+	DriverAge  int     `arg:"driver_age,positional,required,min=16,max=100"`
+	Region     string  `arg:"region,positional,required,nonempty"`
+	Coverage   string  `arg:"coverage,default=collision,oneof=liability|collision|comprehensive"`
+	Deductible float64 `arg:"deductible,min=0"`
+	Make       string  `arg:"make,max=50"`
+	Year       int     `arg:"year,min=1900,max=2100"`
+}
+
+var commands = []meta.Command{
+	{Path: commandPath("help"), Description: "car.command.help", Role: auth.RoleUser},
+	{Path: commandPath("get"), Args: args.Usage(idArgs{}), Description: "car.command.get", Role: auth.RoleUser},
+	{Path: commandPath("list"), Args: args.Usage(listArgs{}), Description: "car.command.list", Role: auth.RoleUser},
+	{Path: commandPath("new"), Args: args.Usage(newArgs{}), Description: "car.command.new", Role: auth.RoleAgent, PrivateOnly: true},
+	{Path: commandPath("edit"), Args: args.Usage(editArgs{}), Description: "car.command.edit", Role: auth.RoleAgent, PrivateOnly: true},
+	{Path: commandPath("delete"), Args: args.Usage(idArgs{}), Description: "car.command.delete", Role: auth.RoleAgent, PrivateOnly: true},
+	{Path: commandPath("watch"), Args: args.Usage(idArgs{}), Description: "car.command.watch", Role: auth.RoleUser},
+	{Path: commandPath("unwatch"), Args: args.Usage(idArgs{}), Description: "car.command.unwatch", Role: auth.RoleUser},
+	{Path: commandPath("quote"), Args: args.Usage(quoteArgs{}), Description: "car.command.quote", Role: auth.RoleAgent},
+}
+
+func commandPath(name string) path.CommandPath {
+	return path.CommandPath{CommandName: name, Domain: "insurance", Subdomain: "car"}
+}
+
+func (c CarCommanderImpl) Commands() []meta.Command {
+	return commands
+}
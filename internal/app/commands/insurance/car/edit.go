@@ -0,0 +1,171 @@
+package car
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/args"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+)
+
+// pendingEdit is an edit which conflicted with a change made by someone else.
+// Only the fields the user changed are applied again, so the other change is kept.
+type pendingEdit struct {
+	before, after insurance.Car
+}
+
+func (e pendingEdit) apply(car *insurance.Car) {
+	if e.after.Title != e.before.Title {
+		car.Title = e.after.Title
+	}
+	if e.after.Make != e.before.Make {
+		car.Make = e.after.Make
+	}
+	if e.after.Year != e.before.Year {
+		car.Year = e.after.Year
+	}
+}
+
+func (c *CarCommanderImpl) Edit(inputMsg *tgbotapi.Message) error {
+	var target editArgs
+	if err := args.ParseMessage(inputMsg, &target); err != nil {
+		return err
+	}
+
+	car, err := c.service.Describe(target.ID)
+	if err != nil {
+		return serviceError(err, target.ID)
+	}
+
+	parsed := editArgs{Title: car.Title, Make: car.Make, Year: car.Year}
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+	edit := pendingEdit{before: *car, after: *car}
+	edit.after.Title, edit.after.Make, edit.after.Year = parsed.Title, parsed.Make, parsed.Year
+	if parsed.Version != 0 {
+		car.Version = parsed.Version
+	}
+
+	return c.applyEdit(inputMsg.Chat.ID, c.localizer.For(inputMsg.From), edit, *car)
+}
+
+// editCallback returns the data of the edit button of the card, e.g. "2:3" for version 3 of car 2.
+func editCallback(car insurance.Car) path.CallbackPath {
+	return path.CallbackPath{
+		Domain:       "insurance",
+		Subdomain:    "car",
+		CallbackName: "edit",
+		CallbackData: fmt.Sprintf("%d:%d", car.ID, car.Version),
+	}
+}
+
+// editCommand is the edit command over the version of the car, the user adds the fields to change.
+// Fields left out are not changed, so an edit applied again after a conflict keeps the other changes.
+func editCommand(car insurance.Car) string {
+	return fmt.Sprintf("%s %d version=%d", commandPath("edit"), car.ID, car.Version)
+}
+
+// CallbackEdit handles both edit buttons: the one of the card offers the edit command over the version
+// the card shows, the one of a conflict applies the edit again over the current version of the car.
+func (c *CarCommanderImpl) CallbackEdit(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
+	if callback.Message == nil {
+		return nil
+	}
+
+	var shown insurance.Car
+	if _, err := fmt.Sscanf(callbackPath.CallbackData, "%d:%d", &shown.ID, &shown.Version); err == nil {
+		return c.offerEdit(callback, shown)
+	}
+
+	value, found := c.edits.take(callbackPath.CallbackData)
+	if !found {
+		return cmderr.Conflict(nil, "car.edit.expired")
+	}
+	edit := value.(pendingEdit)
+
+	car, err := c.service.Describe(edit.before.ID)
+	if err != nil {
+		return serviceError(err, edit.before.ID)
+	}
+
+	return c.applyEdit(callback.Message.Chat.ID, c.localizer.For(callback.From), edit, *car)
+}
+
+// offerEdit sends the edit command over the version the card has shown.
+func (c *CarCommanderImpl) offerEdit(callback *tgbotapi.CallbackQuery, shown insurance.Car) error {
+	msg, err := c.renderer.Message(callback.Message.Chat.ID, "edit", c.localizer.For(callback.From), editForm{
+		Car:     shown,
+		Command: editCommand(shown),
+	})
+	if err != nil {
+		return cmderr.Internal(err)
+	}
+
+	_, err = c.bot.Send(msg)
+	if err != nil {
+		log.Printf("CarCommander.CallbackEdit: error sending reply message to chat - %v", err)
+	}
+
+	return nil
+}
+
+// editForm is rendered by the "edit" template.
+type editForm struct {
+	Car     insurance.Car
+	Command string
+}
+
+// applyEdit updates the car made over car.Version, on a conflict it shows the current car and offers to retry.
+func (c *CarCommanderImpl) applyEdit(chatID int64, p i18n.Printer, edit pendingEdit, car insurance.Car) error {
+	edit.apply(&car)
+
+	err := c.service.Update(car.ID, car)
+	switch {
+	case errors.Is(err, carService.ErrConflict):
+		return c.offerRetry(chatID, p, edit)
+	case err != nil:
+		return serviceError(err, car.ID)
+	}
+
+	c.sendMessageToUser(chatID, p.T("car.edited", car.ID))
+
+	return nil
+}
+
+func (c *CarCommanderImpl) offerRetry(chatID int64, p i18n.Printer, edit pendingEdit) error {
+	current, err := c.service.Describe(edit.before.ID)
+	if err != nil {
+		return serviceError(err, edit.before.ID)
+	}
+
+	msg, err := c.renderer.Message(chatID, "conflict", p, current)
+	if err != nil {
+		return cmderr.Internal(err)
+	}
+
+	callbackPath := path.CallbackPath{
+		Domain:       "insurance",
+		Subdomain:    "car",
+		CallbackName: "edit",
+		CallbackData: c.edits.put(edit),
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("car.edit.retry"), callbackPath.String()),
+		),
+	)
+
+	_, err = c.bot.Send(msg)
+	if err != nil {
+		log.Printf("CarCommander.Edit: error sending reply message to chat - %v", err)
+	}
+
+	return nil
+}
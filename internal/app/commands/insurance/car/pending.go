@@ -0,0 +1,58 @@
+package car
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+)
+
+const (
+	// pendingQuotes is the number of unsaved quotes kept for the save button.
+	pendingQuotes = 1000
+	// pendingEdits is the number of conflicting edits kept for the retry button.
+	pendingEdits = 1000
+)
+
+// pendingCache keeps the recent values offered to be applied by a button under short random tokens
+// fitting into callback data.
+type pendingCache struct {
+	mu      sync.Mutex
+	limit   int
+	byToken map[string]interface{}
+	recent  []string
+}
+
+func newPendingCache(limit int) *pendingCache {
+	return &pendingCache{limit: limit, byToken: make(map[string]interface{})}
+}
+
+func (q *pendingCache) put(pending interface{}) string {
+	token := make([]byte, 9)
+	if _, err := rand.Read(token); err != nil {
+		panic("pendingCache: no random source - " + err.Error())
+	}
+	key := base64.RawURLEncoding.EncodeToString(token)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.byToken[key] = pending
+	q.recent = append(q.recent, key)
+	if len(q.recent) > q.limit {
+		delete(q.byToken, q.recent[0])
+		q.recent = q.recent[1:]
+	}
+
+	return key
+}
+
+// take returns the value once, so pressing the button twice does not apply it again.
+func (q *pendingCache) take(token string) (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending, found := q.byToken[token]
+	delete(q.byToken, token)
+
+	return pending, found
+}
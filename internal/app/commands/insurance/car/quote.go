@@ -0,0 +1,125 @@
+package car
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/args"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+	"github.com/ozonmp/omp-bot/internal/service/insurance/rating"
+)
+
+// quoteView is what the quote template renders.
+type quoteView struct {
+	Car   insurance.Car
+	Quote insurance.Quote
+}
+
+// Quote rates the car and offers to save the quote, the make and the year may be given when the car has none.
+func (c *CarCommanderImpl) Quote(inputMsg *tgbotapi.Message) error {
+	var parsed quoteArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	car, err := c.service.Describe(parsed.ID)
+	if err != nil {
+		return serviceError(err, parsed.ID)
+	}
+	if parsed.Make != "" {
+		car.Make = parsed.Make
+	}
+	if parsed.Year != 0 {
+		car.Year = parsed.Year
+	}
+
+	quote, err := c.engine.Quote(rating.Request{
+		Car:        *car,
+		DriverAge:  parsed.DriverAge,
+		Region:     parsed.Region,
+		Coverage:   insurance.Coverage(parsed.Coverage),
+		Deductible: insurance.MoneyFromFloat(parsed.Deductible),
+		At:         time.Unix(int64(inputMsg.Date), 0).UTC(),
+	})
+	if err != nil {
+		return quoteError(err, "/"+inputMsg.Command()+" "+args.Usage(quoteArgs{}))
+	}
+
+	p := c.localizer.For(inputMsg.From)
+	msg, err := c.renderer.Message(inputMsg.Chat.ID, "quote", p, quoteView{Car: *car, Quote: quote})
+	if err != nil {
+		return cmderr.Internal(err)
+	}
+
+	callbackPath := path.CallbackPath{
+		Domain:       "insurance",
+		Subdomain:    "car",
+		CallbackName: "quote",
+		CallbackData: c.quotes.put(pendingQuote{car: *car, quote: quote}),
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("quote.save"), callbackPath.String()),
+		),
+	)
+
+	_, err = c.bot.Send(msg)
+	if err != nil {
+		log.Printf("CarCommander.Quote: error sending reply message to chat - %v", err)
+	}
+
+	return nil
+}
+
+// quoteError reports unrated attributes as wrong arguments.
+func quoteError(err error, usage string) error {
+	var unknown *rating.UnknownValueError
+	switch {
+	case errors.As(err, &unknown):
+		return cmderr.InvalidArguments(i18n.M("args.one_of", unknown.Attribute, strings.Join(unknown.Known, ", ")), usage)
+	case errors.Is(err, rating.ErrUnknownYear):
+		return cmderr.InvalidArguments(i18n.M("quote.year_unknown"), usage)
+	default:
+		return cmderr.Internal(err)
+	}
+}
+
+// CallbackQuote saves the pending quote to the car along with the rated make and year.
+// The update is made over the version the quote was calculated for, so a car changed meanwhile is not overwritten.
+func (c *CarCommanderImpl) CallbackQuote(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
+	if callback.Message == nil {
+		return nil
+	}
+
+	value, found := c.quotes.take(callbackPath.CallbackData)
+	if !found {
+		return cmderr.Conflict(nil, "quote.expired")
+	}
+	pending := value.(pendingQuote)
+
+	car := pending.car
+	car.Quote = &pending.quote
+	err := c.service.Update(car.ID, car)
+	switch {
+	case errors.Is(err, carService.ErrConflict):
+		return cmderr.Conflict(err, "quote.conflict", car.ID)
+	case err != nil:
+		return serviceError(err, car.ID)
+	}
+
+	c.sendMessageToUser(callback.Message.Chat.ID, c.localizer.For(callback.From).T("quote.saved", car.ID))
+
+	return nil
+}
+
+type pendingQuote struct {
+	car   insurance.Car
+	quote insurance.Quote
+}
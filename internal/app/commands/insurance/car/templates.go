@@ -0,0 +1,27 @@
+package car
+
+import "github.com/ozonmp/omp-bot/internal/app/render"
+
+// templates render cars, every row and field is kept on its own line so long lists are split safely.
+var templates = render.New(render.ModeHTML).
+	MustAdd("card", `{{bold (t "car.card.title" .ID)}}
+{{t "car.card.name"}}: {{.Title}}{{if .Make}}
+{{t "car.card.make"}}: {{.Make}}{{end}}{{if .Year}}
+{{t "car.card.year"}}: {{.Year}}{{end}}{{with .Quote}}
+{{t "car.card.quote" .Premium (t (printf "policy.coverage.%s" .Coverage)) (date .QuotedAt)}}{{end}}{{if .Version}}
+{{t "car.card.version"}}: {{.Version}}{{end}}`).
+	MustAdd("edit", `{{t "car.edit.form" .Car.ID .Car.Version}}
+
+{{code .Command}}`).
+	MustAdd("conflict", `{{t "car.edit.conflict" .ID}}
+
+{{template "card" .}}`).
+	MustAdd("row", `{{code .ID}} {{.Title}}`).
+	MustAdd("list", `{{bold (t "car.list.header")}}
+{{range .}}
+{{template "row" .}}{{end}}`).
+	MustAdd("quote", `{{bold (t "quote.title" .Car.Title .Car.ID)}}
+{{t "quote.request" (t (printf "policy.coverage.%s" .Quote.Coverage)) .Quote.DriverAge .Quote.Region .Quote.Deductible}}
+{{range .Quote.Items}}
+{{t (printf "quote.item.%s" .Name)}}{{if .Detail}} ({{.Detail}}){{end}}: {{if ne .Factor 1.0}}×{{printf "%.2f" .Factor}} {{end}}{{if ge .Amount 0}}+{{end}}{{.Amount}}{{end}}
+{{bold (t "quote.premium" .Quote.Premium)}}`)
@@ -0,0 +1,55 @@
+package claim
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+// statusCallback returns the data of the button moving the claim to the status, e.g. "3:approved".
+func statusCallback(claimID uint64, status insurance.ClaimStatus) string {
+	return path.CallbackPath{
+		Domain:       "insurance",
+		Subdomain:    "claim",
+		CallbackName: "status",
+		CallbackData: fmt.Sprintf("%d:%s", claimID, status),
+	}.String()
+}
+
+// CallbackStatus moves the claim to the status of the pressed button and refreshes the card.
+func (c *ClaimCommanderImpl) CallbackStatus(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
+	parts := strings.SplitN(callbackPath.CallbackData, ":", 2)
+	if len(parts) != 2 {
+		return cmderr.Internal(fmt.Errorf("malformed claim status callback %q", callbackPath.CallbackData))
+	}
+	claimID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return cmderr.Internal(fmt.Errorf("malformed claim status callback %q: %w", callbackPath.CallbackData, err))
+	}
+
+	claim, err := c.transition(claimID, insurance.ClaimStatus(parts[1]))
+	if err != nil {
+		return err
+	}
+
+	text, markup, err := c.card(*claim, c.localizer.For(callback.From))
+	if err != nil {
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = c.renderer.Mode()
+	edit.ReplyMarkup = &markup
+	_, err = c.bot.Send(edit)
+	if err != nil {
+		log.Printf("ClaimCommanderImpl.CallbackStatus: error editing the claim card - %v", err)
+	}
+
+	return nil
+}
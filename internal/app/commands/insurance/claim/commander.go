@@ -0,0 +1,357 @@
+package claim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/args"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/render"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+	userSettings "github.com/ozonmp/omp-bot/internal/app/settings"
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+	claimService "github.com/ozonmp/omp-bot/internal/service/insurance/claim"
+)
+
+type ClaimCommander interface {
+	Help(inputMsg *tgbotapi.Message) error
+	Get(inputMsg *tgbotapi.Message) error
+	List(inputMsg *tgbotapi.Message) error
+
+	New(inputMsg *tgbotapi.Message) error
+	Photo(inputMsg *tgbotapi.Message) error
+	Status(inputMsg *tgbotapi.Message) error
+}
+
+type ClaimCommanderImpl struct {
+	bot       sender.Sender
+	service   claimService.ClaimService
+	cars      carService.CarService
+	localizer *i18n.Localizer
+	renderer  *render.Renderer
+	settings  *userSettings.Store
+}
+
+// claimView is a claim with the title of its car for templates.
+type claimView struct {
+	insurance.Claim
+	Car string
+}
+
+func (c *ClaimCommanderImpl) Help(inputMsg *tgbotapi.Message) error {
+	msg := tgbotapi.NewMessage(inputMsg.Chat.ID, meta.Help(commands, c.localizer.For(inputMsg.From)))
+
+	_, err := c.bot.Send(msg)
+	if err != nil {
+		log.Printf("InsuranceClaimCommander.Help: error sending reply message to chat - %v", err)
+	}
+
+	return nil
+}
+
+func (c *ClaimCommanderImpl) Get(inputMsg *tgbotapi.Message) error {
+	var parsed idArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	claim, err := c.service.Describe(parsed.ID)
+	if err != nil {
+		return serviceError(err, parsed.ID)
+	}
+
+	text, markup, err := c.card(*claim, c.localizer.For(inputMsg.From))
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(inputMsg.Chat.ID, text)
+	msg.ParseMode = c.renderer.Mode()
+	if len(markup.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = markup
+	}
+	_, err = c.bot.Send(msg)
+	if err != nil {
+		log.Printf("ClaimCommander.Get: error sending reply message to chat - %v", err)
+	}
+
+	for _, fileID := range claim.Photos {
+		_, err = c.bot.Send(tgbotapi.NewPhotoShare(inputMsg.Chat.ID, fileID))
+		if err != nil {
+			log.Printf("ClaimCommander.Get: error sending claim photo to chat - %v", err)
+		}
+	}
+
+	return nil
+}
+
+// card renders the claim with buttons moving it to the next statuses of the workflow.
+func (c *ClaimCommanderImpl) card(claim insurance.Claim, p i18n.Printer) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	view := claimView{Claim: claim, Car: fmt.Sprintf("#%d", claim.CarID)}
+	if car, err := c.cars.Describe(claim.CarID); err == nil {
+		view.Car = fmt.Sprintf("%s (#%d)", car.Title, claim.CarID)
+	}
+
+	text, err := c.renderer.Render("card", p, view)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, cmderr.Internal(err)
+	}
+
+	buttons := []tgbotapi.InlineKeyboardButton{}
+	for _, next := range claim.Status.Next() {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(
+			p.T("claim.action."+string(next)),
+			statusCallback(claim.ID, next),
+		))
+	}
+	markup := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if len(buttons) > 0 {
+		markup.InlineKeyboard = append(markup.InlineKeyboard, buttons)
+	}
+
+	return text, markup, nil
+}
+
+func (c *ClaimCommanderImpl) listPage(chatID int64, cursor, pageSize uint64, p i18n.Printer) (*tgbotapi.MessageConfig, error) {
+	claims, err := c.service.List(cursor, pageSize)
+	if err != nil {
+		return nil, cmderr.Internal(err)
+	}
+	if len(claims) == 0 {
+		return nil, cmderr.NotFound(nil, "claim.list.end")
+	}
+
+	msg, err := c.renderer.Message(chatID, "list", p, claims)
+	if err != nil {
+		return nil, cmderr.Internal(err)
+	}
+
+	if uint64(len(claims)) < pageSize {
+		return &msg, nil
+	}
+
+	serializedData, _ := json.Marshal(CallbackListData{
+		Offset:   int(cursor + pageSize),
+		PageSize: int(pageSize),
+	})
+
+	callbackPath := path.CallbackPath{
+		Domain:       "insurance",
+		Subdomain:    "claim",
+		CallbackName: "list",
+		CallbackData: string(serializedData),
+	}
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("claim.list.next"), callbackPath.String()),
+		),
+	)
+	return &msg, nil
+}
+
+func (c *ClaimCommanderImpl) List(inputMsg *tgbotapi.Message) error {
+	pageSize := uint64(userSettings.DefaultPageSize)
+	if inputMsg.From != nil {
+		settings, err := c.settings.Get(int64(inputMsg.From.ID))
+		if err != nil {
+			return cmderr.Internal(err)
+		}
+		pageSize = settings.PageSizeOr(pageSize)
+	}
+
+	parsed := listArgs{PageSize: pageSize}
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	msg, err := c.listPage(inputMsg.Chat.ID, 0, parsed.PageSize, c.localizer.For(inputMsg.From))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.bot.Send(*msg)
+	if err != nil {
+		log.Printf("ClaimCommander.List: error sending reply message to chat - %v", err)
+	}
+
+	return nil
+}
+
+// New files a claim, when the command replies to a photo the photo is attached to the claim.
+func (c *ClaimCommanderImpl) New(inputMsg *tgbotapi.Message) error {
+	var parsed newArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	if _, err := c.cars.Describe(parsed.CarID); err != nil {
+		if errors.Is(err, carService.ErrNotFound) {
+			return cmderr.NotFound(err, "car.not_found", parsed.CarID)
+		}
+		return cmderr.Internal(err)
+	}
+
+	claim := insurance.Claim{
+		CarID:       parsed.CarID,
+		Description: parsed.Description,
+		Amount:      insurance.MoneyFromFloat(parsed.Amount),
+		FiledAt:     time.Unix(int64(inputMsg.Date), 0).UTC(),
+	}
+	if inputMsg.From != nil {
+		claim.FiledBy = int64(inputMsg.From.ID)
+	}
+	if fileID, found := repliedPhoto(inputMsg); found {
+		claim.Photos = []string{fileID}
+	}
+
+	id, err := c.service.Create(claim)
+	if err != nil {
+		return cmderr.Internal(err)
+	}
+
+	c.sendMessageToUser(inputMsg.Chat.ID, c.localizer.For(inputMsg.From).T("claim.added", id))
+
+	return nil
+}
+
+// Photo attaches the photo the command replies to.
+func (c *ClaimCommanderImpl) Photo(inputMsg *tgbotapi.Message) error {
+	var parsed idArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	fileID, found := repliedPhoto(inputMsg)
+	if !found {
+		return cmderr.InvalidArguments(i18n.M("claim.photo.reply"), "/"+inputMsg.Command()+" "+args.Usage(idArgs{}))
+	}
+
+	if err := c.service.AddPhotos(parsed.ID, fileID); err != nil {
+		return serviceError(err, parsed.ID)
+	}
+
+	c.sendMessageToUser(inputMsg.Chat.ID, c.localizer.For(inputMsg.From).T("claim.photo.added", parsed.ID))
+
+	return nil
+}
+
+// repliedPhoto returns the largest size of the photo the message replies to.
+func repliedPhoto(inputMsg *tgbotapi.Message) (string, bool) {
+	if inputMsg.ReplyToMessage == nil || inputMsg.ReplyToMessage.Photo == nil {
+		return "", false
+	}
+	sizes := *inputMsg.ReplyToMessage.Photo
+	if len(sizes) == 0 {
+		return "", false
+	}
+
+	return sizes[len(sizes)-1].FileID, true
+}
+
+func (c *ClaimCommanderImpl) Status(inputMsg *tgbotapi.Message) error {
+	var parsed statusArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	claim, err := c.transition(parsed.ID, insurance.ClaimStatus(parsed.Status))
+	if err != nil {
+		return err
+	}
+
+	p := c.localizer.For(inputMsg.From)
+	c.sendMessageToUser(inputMsg.Chat.ID, p.T("claim.status_changed", claim.ID, i18n.M("claim.status."+string(claim.Status))))
+
+	return nil
+}
+
+func (c *ClaimCommanderImpl) transition(claimID uint64, next insurance.ClaimStatus) (*insurance.Claim, error) {
+	claim, err := c.service.Transition(claimID, next)
+
+	var transitionErr *insurance.TransitionError
+	if errors.As(err, &transitionErr) {
+		return nil, cmderr.Conflict(err, "claim.transition",
+			claimID, i18n.M("claim.status."+string(transitionErr.From)), i18n.M("claim.status."+string(transitionErr.To)))
+	}
+	if err != nil {
+		return nil, serviceError(err, claimID)
+	}
+
+	return claim, nil
+}
+
+func (c *ClaimCommanderImpl) sendMessageToUser(chatId int64, msgToShow string) {
+	msg := tgbotapi.NewMessage(
+		chatId,
+		msgToShow,
+	)
+	_, err := c.bot.Send(msg)
+	if err != nil {
+		log.Printf("ClaimCommander: error sending reply message to chat - %v", err)
+	}
+}
+
+// serviceError maps service failures for a single claim to command errors.
+func serviceError(err error, claimID uint64) error {
+	if errors.Is(err, claimService.ErrNotFound) {
+		return cmderr.NotFound(err, "claim.not_found", claimID)
+	}
+
+	return cmderr.Internal(err)
+}
+
+func (c ClaimCommanderImpl) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
+	switch callbackPath.CallbackName {
+	case "list":
+		return c.CallbackList(callback, callbackPath)
+	case "status":
+		return c.CallbackStatus(callback, callbackPath)
+	default:
+		return cmderr.UnknownCommand(callbackPath.CallbackName)
+	}
+}
+
+func (c ClaimCommanderImpl) HandleCommand(message *tgbotapi.Message, commandPath path.CommandPath) error {
+	switch commandPath.CommandName {
+	case "help":
+		return c.Help(message)
+	case "list":
+		return c.List(message)
+	case "get":
+		return c.Get(message)
+	case "new":
+		return c.New(message)
+	case "photo":
+		return c.Photo(message)
+	case "status":
+		return c.Status(message)
+	default:
+		return cmderr.UnknownCommand(commandPath.String())
+	}
+}
+
+func NewClaimCommander(
+	bot sender.Sender,
+	service claimService.ClaimService,
+	cars carService.CarService,
+	localizer *i18n.Localizer,
+	settings *userSettings.Store,
+) ClaimCommanderImpl {
+	return ClaimCommanderImpl{
+		bot:       bot,
+		service:   service,
+		cars:      cars,
+		localizer: localizer,
+		renderer:  templates,
+		settings:  settings,
+	}
+}
@@ -0,0 +1,44 @@
+package claim
+
+import (
+	"github.com/ozonmp/omp-bot/internal/app/args"
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+)
+
+type idArgs struct {
+	ID uint64 `arg:"id,positional,required"`
+}
+
+type listArgs struct {
+	PageSize uint64 `arg:"page_size,positional,min=1,max=50"`
+}
+
+type newArgs struct {
+	CarID       uint64  `arg:"car,positional,required"`
+	Amount      float64 `arg:"amount,positional,required,min=0"`
+	Description string  `arg:"description,rest,required,nonempty,max=1000"`
+}
+
+type statusArgs struct {
+	ID     uint64 `arg:"id,positional,required"`
+	Status string `arg:"status,positional,required,oneof=under_review|approved|rejected|paid"`
+}
+
+var commands = []meta.Command{
+	{Path: commandPath("help"), Description: "claim.command.help", Role: auth.RoleUser},
+	{Path: commandPath("get"), Args: args.Usage(idArgs{}), Description: "claim.command.get", Role: auth.RoleUser},
+	{Path: commandPath("list"), Args: args.Usage(listArgs{}), Description: "claim.command.list", Role: auth.RoleUser},
+	{Path: commandPath("new"), Args: args.Usage(newArgs{}), Description: "claim.command.new", Role: auth.RoleUser},
+	{Path: commandPath("photo"), Args: args.Usage(idArgs{}), Description: "claim.command.photo", Role: auth.RoleUser},
+	{Path: commandPath("status"), Args: args.Usage(statusArgs{}), Description: "claim.command.status", Role: auth.RoleAgent},
+}
+
+func commandPath(name string) path.CommandPath {
+	return path.CommandPath{CommandName: name, Domain: "insurance", Subdomain: "claim"}
+}
+
+func (c ClaimCommanderImpl) Commands() []meta.Command {
+	return commands
+}
@@ -0,0 +1,17 @@
+package claim
+
+import "github.com/ozonmp/omp-bot/internal/app/render"
+
+var templates = render.New(render.ModeHTML).
+	MustAdd("card", `{{bold (t "claim.card.title" .ID)}}
+{{t "claim.card.car"}}: {{.Car}}
+{{t "claim.card.amount"}}: {{.Amount}}
+{{t "claim.card.status"}}: {{t (printf "claim.status.%s" .Status)}}
+{{t "claim.card.filed"}}: {{date .FiledAt}}
+{{t "claim.card.photos"}}: {{len .Photos}}
+
+{{.Description}}`).
+	MustAdd("row", `{{code .ID}} {{.Amount}}, {{t (printf "claim.status.%s" .Status)}}, {{.Description}}`).
+	MustAdd("list", `{{bold (t "claim.list.header")}}
+{{range .}}
+{{template "row" .}}{{end}}`)
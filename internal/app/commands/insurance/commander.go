@@ -2,46 +2,95 @@ package insurance
 
 import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
 	"github.com/ozonmp/omp-bot/internal/app/commands/insurance/car"
+	"github.com/ozonmp/omp-bot/internal/app/commands/insurance/claim"
+	"github.com/ozonmp/omp-bot/internal/app/commands/insurance/policy"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
 	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+	userSettings "github.com/ozonmp/omp-bot/internal/app/settings"
+	"github.com/ozonmp/omp-bot/internal/app/watch"
 	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+	claimService "github.com/ozonmp/omp-bot/internal/service/insurance/claim"
+	policyService "github.com/ozonmp/omp-bot/internal/service/insurance/policy"
+	"github.com/ozonmp/omp-bot/internal/service/insurance/rating"
 	"log"
 )
 
 type Commander interface {
-	HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath)
-	HandleCommand(message *tgbotapi.Message, commandPath path.CommandPath)
+	HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error
+	HandleCommand(message *tgbotapi.Message, commandPath path.CommandPath) error
+	Commands() []meta.Command
+}
+
+// Services are the services of the insurance subdomains.
+type Services struct {
+	Car    carService.CarService
+	Policy policyService.PolicyService
+	Claim  claimService.ClaimService
+	// Rating quotes car premiums.
+	Rating rating.Engine
+	// Watches keeps the chats watching changes of the entities.
+	Watches *watch.Store
 }
 
 type InsuranceCommander struct {
-	bot          *tgbotapi.BotAPI
-	carCommander Commander
+	bot             sender.Sender
+	carCommander    Commander
+	policyCommander Commander
+	claimCommander  Commander
 }
 
 func NewInsuranceCommander(
-	bot *tgbotapi.BotAPI,
+	bot sender.Sender,
+	services Services,
+	localizer *i18n.Localizer,
+	settings *userSettings.Store,
 ) *InsuranceCommander {
 	return &InsuranceCommander{
 		bot: bot,
 		// carCommander
-		carCommander: car.NewCarCommander(bot, carService.NewDummyCarService()),
+		carCommander:    car.NewCarCommander(bot, services.Car, localizer, settings, services.Rating, services.Watches),
+		policyCommander: policy.NewPolicyCommander(bot, services.Policy, services.Car, localizer, settings),
+		claimCommander:  claim.NewClaimCommander(bot, services.Claim, services.Car, localizer, settings),
 	}
 }
 
-func (c *InsuranceCommander) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) {
+// Commands returns the commands of all insurance subdomains.
+func (c *InsuranceCommander) Commands() []meta.Command {
+	commands := append([]meta.Command{}, c.carCommander.Commands()...)
+
+	commands = append(commands, c.policyCommander.Commands()...)
+
+	return append(commands, c.claimCommander.Commands()...)
+}
+
+func (c *InsuranceCommander) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
 	switch callbackPath.Subdomain {
 	case "car":
-		c.carCommander.HandleCallback(callback, callbackPath)
+		return c.carCommander.HandleCallback(callback, callbackPath)
+	case "policy":
+		return c.policyCommander.HandleCallback(callback, callbackPath)
+	case "claim":
+		return c.claimCommander.HandleCallback(callback, callbackPath)
 	default:
-		log.Printf("InsuranceCommander.HandleCallback: unknown subdumain - %s", callbackPath.Subdomain)
+		log.Printf("InsuranceCommander.HandleCallback: unknown subdomain - %s", callbackPath.Subdomain)
+		return cmderr.UnknownCommand(callbackPath.String())
 	}
 }
 
-func (c *InsuranceCommander) HandleCommand(msg *tgbotapi.Message, commandPath path.CommandPath) {
+func (c *InsuranceCommander) HandleCommand(msg *tgbotapi.Message, commandPath path.CommandPath) error {
 	switch commandPath.Subdomain {
 	case "car":
-		c.carCommander.HandleCommand(msg, commandPath)
+		return c.carCommander.HandleCommand(msg, commandPath)
+	case "policy":
+		return c.policyCommander.HandleCommand(msg, commandPath)
+	case "claim":
+		return c.claimCommander.HandleCommand(msg, commandPath)
 	default:
-		log.Printf("InsuranceCommander.HandleCommand: unknown subdumain - %s", commandPath.Subdomain)
+		log.Printf("InsuranceCommander.HandleCommand: unknown subdomain - %s", commandPath.Subdomain)
+		return cmderr.UnknownCommand(commandPath.String())
 	}
 }
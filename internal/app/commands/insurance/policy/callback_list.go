@@ -0,0 +1,42 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+)
+
+type CallbackListData struct {
+	Offset   int `json:"offset"`
+	PageSize int `json:"page_size"`
+}
+
+func (c *PolicyCommanderImpl) CallbackList(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
+	parsedData := CallbackListData{}
+	err := json.Unmarshal([]byte(callbackPath.CallbackData), &parsedData)
+	if err != nil {
+		return cmderr.Internal(err)
+	}
+	if parsedData.Offset < 0 || parsedData.PageSize <= 0 {
+		return cmderr.Internal(fmt.Errorf("list page is out of range: %+v", parsedData))
+	}
+	msg, err := c.listPage(
+		callback.Message.Chat.ID,
+		uint64(parsedData.Offset),
+		uint64(parsedData.PageSize),
+		c.localizer.For(callback.From),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = c.bot.Send(msg)
+	if err != nil {
+		log.Printf("PolicyCommanderImpl.CallbackList: error sending reply message to chat - %v", err)
+	}
+
+	return nil
+}
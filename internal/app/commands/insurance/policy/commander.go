@@ -0,0 +1,337 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/args"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/render"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+	userSettings "github.com/ozonmp/omp-bot/internal/app/settings"
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+	policyService "github.com/ozonmp/omp-bot/internal/service/insurance/policy"
+)
+
+type PolicyCommander interface {
+	Help(inputMsg *tgbotapi.Message) error
+	Get(inputMsg *tgbotapi.Message) error
+	List(inputMsg *tgbotapi.Message) error
+	Delete(inputMsg *tgbotapi.Message) error
+
+	New(inputMsg *tgbotapi.Message) error
+	Edit(inputMsg *tgbotapi.Message) error
+}
+
+type PolicyCommanderImpl struct {
+	bot       sender.Sender
+	service   policyService.PolicyService
+	cars      carService.CarService
+	localizer *i18n.Localizer
+	renderer  *render.Renderer
+	settings  *userSettings.Store
+}
+
+// policyView is a policy with the titles of its cars for templates.
+type policyView struct {
+	insurance.Policy
+	Cars string
+}
+
+func (c *PolicyCommanderImpl) Help(inputMsg *tgbotapi.Message) error {
+	msg := tgbotapi.NewMessage(inputMsg.Chat.ID, meta.Help(commands, c.localizer.For(inputMsg.From)))
+
+	_, err := c.bot.Send(msg)
+	if err != nil {
+		log.Printf("InsurancePolicyCommander.Help: error sending reply message to chat - %v", err)
+	}
+
+	return nil
+}
+
+func (c *PolicyCommanderImpl) Get(inputMsg *tgbotapi.Message) error {
+	var parsed idArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	policy, err := c.service.Describe(parsed.ID)
+	if err != nil {
+		return serviceError(err, parsed.ID)
+	}
+
+	msg, err := c.renderer.Message(inputMsg.Chat.ID, "card", c.localizer.For(inputMsg.From), c.view(*policy))
+	if err != nil {
+		return cmderr.Internal(err)
+	}
+
+	_, err = c.bot.Send(msg)
+	if err != nil {
+		log.Printf("PolicyCommander.Get: error sending reply message to chat - %v", err)
+	}
+
+	return nil
+}
+
+// view lists the cars of the policy by title, removed cars are shown by ID only.
+func (c *PolicyCommanderImpl) view(policy insurance.Policy) policyView {
+	titles := make([]string, 0, len(policy.CarIDs))
+	for _, id := range policy.CarIDs {
+		car, err := c.cars.Describe(id)
+		if err != nil {
+			titles = append(titles, fmt.Sprintf("#%d", id))
+			continue
+		}
+		titles = append(titles, fmt.Sprintf("%s (#%d)", car.Title, id))
+	}
+
+	return policyView{Policy: policy, Cars: strings.Join(titles, ", ")}
+}
+
+func (c *PolicyCommanderImpl) listPage(chatID int64, cursor, pageSize uint64, p i18n.Printer) (*tgbotapi.MessageConfig, error) {
+	policies, err := c.service.List(cursor, pageSize)
+	if err != nil {
+		return nil, cmderr.Internal(err)
+	}
+	if len(policies) == 0 {
+		return nil, cmderr.NotFound(nil, "policy.list.end")
+	}
+
+	msg, err := c.renderer.Message(chatID, "list", p, policies)
+	if err != nil {
+		return nil, cmderr.Internal(err)
+	}
+
+	if uint64(len(policies)) < pageSize {
+		return &msg, nil
+	}
+
+	serializedData, _ := json.Marshal(CallbackListData{
+		Offset:   int(cursor + pageSize),
+		PageSize: int(pageSize),
+	})
+
+	callbackPath := path.CallbackPath{
+		Domain:       "insurance",
+		Subdomain:    "policy",
+		CallbackName: "list",
+		CallbackData: string(serializedData),
+	}
+
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("policy.list.next"), callbackPath.String()),
+		),
+	)
+	return &msg, nil
+}
+
+func (c *PolicyCommanderImpl) List(inputMsg *tgbotapi.Message) error {
+	pageSize := uint64(userSettings.DefaultPageSize)
+	if inputMsg.From != nil {
+		settings, err := c.settings.Get(int64(inputMsg.From.ID))
+		if err != nil {
+			return cmderr.Internal(err)
+		}
+		pageSize = settings.PageSizeOr(pageSize)
+	}
+
+	parsed := listArgs{PageSize: pageSize}
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	msg, err := c.listPage(inputMsg.Chat.ID, 0, parsed.PageSize, c.localizer.For(inputMsg.From))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.bot.Send(*msg)
+	if err != nil {
+		log.Printf("PolicyCommander.List: error sending reply message to chat - %v", err)
+	}
+
+	return nil
+}
+
+func (c *PolicyCommanderImpl) Delete(inputMsg *tgbotapi.Message) error {
+	var parsed idArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	_, err := c.service.Remove(parsed.ID)
+	if err != nil {
+		return serviceError(err, parsed.ID)
+	}
+
+	c.sendMessageToUser(inputMsg.Chat.ID, c.localizer.For(inputMsg.From).T("policy.deleted", parsed.ID))
+
+	return nil
+}
+
+func (c *PolicyCommanderImpl) New(inputMsg *tgbotapi.Message) error {
+	var parsed newArgs
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	policy := insurance.Policy{
+		CarIDs:   parsed.Cars,
+		Coverage: insurance.Coverage(parsed.Coverage),
+		Start:    parsed.Start,
+		End:      parsed.End,
+		Premium:  insurance.MoneyFromFloat(parsed.Premium),
+		Status:   insurance.PolicyStatus(parsed.Status),
+	}
+	if err := c.validate(inputMsg, policy, args.Usage(newArgs{})); err != nil {
+		return err
+	}
+
+	id, err := c.service.Create(policy)
+	if err != nil {
+		return cmderr.Internal(err)
+	}
+
+	c.sendMessageToUser(inputMsg.Chat.ID, c.localizer.For(inputMsg.From).T("policy.added", id))
+
+	return nil
+}
+
+func (c *PolicyCommanderImpl) Edit(inputMsg *tgbotapi.Message) error {
+	var target editArgs
+	if err := args.ParseMessage(inputMsg, &target); err != nil {
+		return err
+	}
+
+	stored, err := c.service.Describe(target.ID)
+	if err != nil {
+		return serviceError(err, target.ID)
+	}
+
+	parsed := editArgs{
+		Cars:     stored.CarIDs,
+		Coverage: string(stored.Coverage),
+		Start:    stored.Start,
+		End:      stored.End,
+		Premium:  float64(stored.Premium) / 100,
+		Status:   string(stored.Status),
+	}
+	if err := args.ParseMessage(inputMsg, &parsed); err != nil {
+		return err
+	}
+
+	policy := insurance.Policy{
+		CarIDs:   parsed.Cars,
+		Coverage: insurance.Coverage(parsed.Coverage),
+		Start:    parsed.Start,
+		End:      parsed.End,
+		Premium:  insurance.MoneyFromFloat(parsed.Premium),
+		Status:   insurance.PolicyStatus(parsed.Status),
+	}
+	if err := c.validate(inputMsg, policy, args.Usage(editArgs{})); err != nil {
+		return err
+	}
+
+	err = c.service.Update(target.ID, policy)
+	if err != nil {
+		return serviceError(err, target.ID)
+	}
+	c.sendMessageToUser(inputMsg.Chat.ID, c.localizer.For(inputMsg.From).T("policy.edited", target.ID))
+
+	return nil
+}
+
+// validate checks what the argument tags cannot: the period and the insured cars.
+func (c *PolicyCommanderImpl) validate(inputMsg *tgbotapi.Message, policy insurance.Policy, usage string) error {
+	if len(policy.CarIDs) == 0 {
+		return cmderr.InvalidArguments(i18n.M("args.empty_list", "cars"), "/"+inputMsg.Command()+" "+usage)
+	}
+	if policy.End.Before(policy.Start) {
+		return cmderr.InvalidArguments(i18n.M("policy.bad_period"), "/"+inputMsg.Command()+" "+usage)
+	}
+
+	for _, id := range policy.CarIDs {
+		if _, err := c.cars.Describe(id); err != nil {
+			if errors.Is(err, carService.ErrNotFound) {
+				return cmderr.NotFound(err, "car.not_found", id)
+			}
+			return cmderr.Internal(err)
+		}
+	}
+
+	return nil
+}
+
+func (c *PolicyCommanderImpl) sendMessageToUser(chatId int64, msgToShow string) {
+	msg := tgbotapi.NewMessage(
+		chatId,
+		msgToShow,
+	)
+	_, err := c.bot.Send(msg)
+	if err != nil {
+		log.Printf("PolicyCommander: error sending reply message to chat - %v", err)
+	}
+}
+
+// serviceError maps service failures for a single policy to command errors.
+func serviceError(err error, policyID uint64) error {
+	if errors.Is(err, policyService.ErrNotFound) {
+		return cmderr.NotFound(err, "policy.not_found", policyID)
+	}
+
+	return cmderr.Internal(err)
+}
+
+func (c PolicyCommanderImpl) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
+	switch callbackPath.CallbackName {
+	case "list":
+		return c.CallbackList(callback, callbackPath)
+	default:
+		return cmderr.UnknownCommand(callbackPath.CallbackName)
+	}
+}
+
+func (c PolicyCommanderImpl) HandleCommand(message *tgbotapi.Message, commandPath path.CommandPath) error {
+	switch commandPath.CommandName {
+	case "help":
+		return c.Help(message)
+	case "list":
+		return c.List(message)
+	case "get":
+		return c.Get(message)
+	case "delete":
+		return c.Delete(message)
+	case "new":
+		return c.New(message)
+	case "edit":
+		return c.Edit(message)
+	default:
+		return cmderr.UnknownCommand(commandPath.String())
+	}
+}
+
+func NewPolicyCommander(
+	bot sender.Sender,
+	service policyService.PolicyService,
+	cars carService.CarService,
+	localizer *i18n.Localizer,
+	settings *userSettings.Store,
+) PolicyCommanderImpl {
+	return PolicyCommanderImpl{
+		bot:       bot,
+		service:   service,
+		cars:      cars,
+		localizer: localizer,
+		renderer:  templates,
+		settings:  settings,
+	}
+}
@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/app/args"
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+)
+
+type idArgs struct {
+	ID uint64 `arg:"id,positional,required"`
+}
+
+type listArgs struct {
+	PageSize uint64 `arg:"page_size,positional,min=1,max=50"`
+}
+
+type newArgs struct {
+	Cars     []uint64  `arg:"cars,positional,required"`
+	Coverage string    `arg:"coverage,positional,required,oneof=liability|collision|comprehensive"`
+	Start    time.Time `arg:"start,positional,required"`
+	End      time.Time `arg:"end,positional,required"`
+	Premium  float64   `arg:"premium,positional,required,min=0"`
+	Status   string    `arg:"status,default=active,oneof=draft|active|expired|cancelled"`
+}
+
+// editArgs are parsed over the values of the edited policy, so omitted arguments keep them.
+type editArgs struct {
+	ID       uint64    `arg:"id,positional,required"`
+	Cars     []uint64  `arg:"cars"`
+	Coverage string    `arg:"coverage,oneof=liability|collision|comprehensive"`
+	Start    time.Time `arg:"start"`
+	End      time.Time `arg:"end"`
+	Premium  float64   `arg:"premium,min=0"`
+	Status   string    `arg:"status,oneof=draft|active|expired|cancelled"`
+}
+
+var commands = []meta.Command{
+	{Path: commandPath("help"), Description: "policy.command.help", Role: auth.RoleUser},
+	{Path: commandPath("get"), Args: args.Usage(idArgs{}), Description: "policy.command.get", Role: auth.RoleUser},
+	{Path: commandPath("list"), Args: args.Usage(listArgs{}), Description: "policy.command.list", Role: auth.RoleUser},
+	{Path: commandPath("new"), Args: args.Usage(newArgs{}), Description: "policy.command.new", Role: auth.RoleAgent, PrivateOnly: true},
+	{Path: commandPath("edit"), Args: args.Usage(editArgs{}), Description: "policy.command.edit", Role: auth.RoleAgent, PrivateOnly: true},
+	{Path: commandPath("delete"), Args: args.Usage(idArgs{}), Description: "policy.command.delete", Role: auth.RoleAgent, PrivateOnly: true},
+}
+
+func commandPath(name string) path.CommandPath {
+	return path.CommandPath{CommandName: name, Domain: "insurance", Subdomain: "policy"}
+}
+
+func (c PolicyCommanderImpl) Commands() []meta.Command {
+	return commands
+}
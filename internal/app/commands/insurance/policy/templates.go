@@ -0,0 +1,16 @@
+package policy
+
+import "github.com/ozonmp/omp-bot/internal/app/render"
+
+// templates render policyView values, every field is kept on its own line so long lists are split safely.
+var templates = render.New(render.ModeHTML).
+	MustAdd("card", `{{bold (t "policy.card.title" .ID)}}
+{{t "policy.card.cars"}}: {{.Cars}}
+{{t "policy.card.coverage"}}: {{t (printf "policy.coverage.%s" .Coverage)}}
+{{t "policy.card.period"}}: {{date .Start}} — {{date .End}}
+{{t "policy.card.premium"}}: {{.Premium}}
+{{t "policy.card.status"}}: {{t (printf "policy.status.%s" .Status)}}`).
+	MustAdd("row", `{{code .ID}} {{t (printf "policy.coverage.%s" .Coverage)}}, {{date .Start}} — {{date .End}}, {{t (printf "policy.status.%s" .Status)}}`).
+	MustAdd("list", `{{bold (t "policy.list.header")}}
+{{range .}}
+{{template "row" .}}{{end}}`)
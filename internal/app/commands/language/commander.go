@@ -0,0 +1,102 @@
+package language
+
+import (
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/args"
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+)
+
+type languageArgs struct {
+	Language string `arg:"language,positional"`
+}
+
+// LanguageCommander lets users override the language taken from their Telegram client.
+type LanguageCommander struct {
+	bot       sender.Sender
+	localizer *i18n.Localizer
+}
+
+func NewLanguageCommander(bot sender.Sender, localizer *i18n.Localizer) *LanguageCommander {
+	return &LanguageCommander{bot: bot, localizer: localizer}
+}
+
+func (c *LanguageCommander) Commands() []meta.Command {
+	return []meta.Command{
+		{Path: path.CommandPath{CommandName: "language"}, Args: args.Usage(languageArgs{}), Description: "language.command", Role: auth.RoleUser},
+	}
+}
+
+// HandleCommand sets the language given as the argument or offers the supported ones.
+func (c *LanguageCommander) HandleCommand(msg *tgbotapi.Message, commandPath path.CommandPath) error {
+	var parsed languageArgs
+	if err := args.ParseMessage(msg, &parsed); err != nil {
+		return err
+	}
+
+	if parsed.Language == "" {
+		return c.offer(msg.Chat.ID, c.localizer.For(msg.From))
+	}
+
+	lang := c.localizer.Match(parsed.Language)
+	if lang == "" {
+		problem := i18n.M("args.one_of", "language", strings.Join(c.localizer.Languages(), ", "))
+		return cmderr.InvalidArguments(problem, "/language "+args.Usage(languageArgs{}))
+	}
+
+	return c.set(msg.Chat.ID, msg.From, lang)
+}
+
+func (c *LanguageCommander) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
+	if callbackPath.CallbackName != "set" || callback.Message == nil {
+		return cmderr.UnknownCommand(callbackPath.String())
+	}
+
+	lang := c.localizer.Match(callbackPath.CallbackData)
+	if lang == "" {
+		return cmderr.UnknownCommand(callbackPath.String())
+	}
+
+	return c.set(callback.Message.Chat.ID, callback.From, lang)
+}
+
+func (c *LanguageCommander) offer(chatID int64, p i18n.Printer) error {
+	var buttons []tgbotapi.InlineKeyboardButton
+	for _, lang := range c.localizer.Languages() {
+		data := path.CallbackPath{Domain: "language", Subdomain: "user", CallbackName: "set", CallbackData: lang}
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(p.T("language.name."+lang), data.String()))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, p.T("language.current", p.T("language.name."+p.Language())))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons)
+	c.send(msg)
+
+	return nil
+}
+
+func (c *LanguageCommander) set(chatID int64, user *tgbotapi.User, lang string) error {
+	if user == nil {
+		return nil
+	}
+	if err := c.localizer.SetLanguage(int64(user.ID), lang); err != nil {
+		return cmderr.Internal(err)
+	}
+
+	p := c.localizer.Printer(lang)
+	c.send(tgbotapi.NewMessage(chatID, p.T("language.changed", p.T("language.name."+lang))))
+
+	return nil
+}
+
+func (c *LanguageCommander) send(msg tgbotapi.Chattable) {
+	if _, err := c.bot.Send(msg); err != nil {
+		log.Printf("LanguageCommander: error sending reply message to chat - %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package menu
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+)
+
+// MenuCommander lets users navigate domain → subdomain → action with inline keyboards
+// instead of typing commands.
+type MenuCommander struct {
+	bot      sender.Sender
+	commands *meta.Registry
+	// roles hide the commands the user cannot run, nil shows all of them
+	roles     auth.Resolver
+	localizer *i18n.Localizer
+	// run handles a synthesized command as if the user has sent it
+	run func(update tgbotapi.Update)
+}
+
+func NewMenuCommander(
+	bot sender.Sender,
+	commands *meta.Registry,
+	roles auth.Resolver,
+	localizer *i18n.Localizer,
+	run func(update tgbotapi.Update),
+) *MenuCommander {
+	return &MenuCommander{bot: bot, commands: commands, roles: roles, localizer: localizer, run: run}
+}
+
+func (c *MenuCommander) Commands() []meta.Command {
+	return []meta.Command{
+		{Path: path.CommandPath{CommandName: "start"}, Args: "[payload]", Description: "start.command", Role: auth.RoleUser},
+	}
+}
+
+// Start greets the user, a deep link payload like get__insurance__car-3 runs "/get__insurance__car 3"
+// and menu-insurance opens the menu of the domain.
+func (c *MenuCommander) Start(msg *tgbotapi.Message) error {
+	p := c.localizer.For(msg.From)
+	payload := strings.TrimSpace(msg.CommandArguments())
+	if payload == "" {
+		return c.welcome(msg.Chat.ID, msg.From, p)
+	}
+
+	parts := strings.Split(payload, "-")
+	if parts[0] == "menu" {
+		return c.send(c.menuMessage(msg.Chat.ID, msg.From, strings.Join(parts[1:], "/"), p))
+	}
+
+	command, found := c.commands.Lookup(parts[0])
+	if !found {
+		return cmderr.UnknownCommand("/" + parts[0])
+	}
+	c.run(tgbotapi.Update{Message: commandMessage(msg.MessageID, msg.From, msg.Chat, command.Name(), parts[1:])})
+
+	return nil
+}
+
+// Show answers plain text messages with the main menu.
+func (c *MenuCommander) Show(msg *tgbotapi.Message) error {
+	return c.send(c.menuMessage(msg.Chat.ID, msg.From, "", c.localizer.For(msg.From)))
+}
+
+func (c *MenuCommander) welcome(chatID int64, user *tgbotapi.User, p i18n.Printer) error {
+	button := p.T("menu.button")
+	greeting := tgbotapi.NewMessage(chatID, p.T("menu.welcome", button))
+	greeting.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(button)),
+	)
+	if err := c.send(greeting); err != nil {
+		return err
+	}
+
+	return c.send(c.menuMessage(chatID, user, "", p))
+}
+
+func (c *MenuCommander) HandleCommand(msg *tgbotapi.Message, commandPath path.CommandPath) error {
+	return cmderr.UnknownCommand(commandPath.String())
+}
+
+func (c *MenuCommander) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
+	if callback.Message == nil {
+		return nil
+	}
+
+	p := c.localizer.For(callback.From)
+	switch callbackPath.CallbackName {
+	case "open":
+		text, markup := c.level(callbackPath.CallbackData, callback.From, p)
+		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+		edit.ReplyMarkup = &markup
+
+		return c.send(edit)
+	case "run":
+		command, found := c.commands.Lookup(callbackPath.CallbackData)
+		if !found {
+			return cmderr.UnknownCommand("/" + callbackPath.CallbackData)
+		}
+		if command.NeedsArgs() {
+			return c.send(tgbotapi.NewMessage(callback.Message.Chat.ID,
+				p.T("menu.send", capitalize(p.T(command.Description)), command.Usage())))
+		}
+
+		c.run(tgbotapi.Update{Message: commandMessage(callback.Message.MessageID, callback.From, callback.Message.Chat, command.Name(), nil)})
+
+		return nil
+	default:
+		return cmderr.UnknownCommand(callbackPath.String())
+	}
+}
+
+func (c *MenuCommander) menuMessage(chatID int64, user *tgbotapi.User, location string, p i18n.Printer) tgbotapi.MessageConfig {
+	text, markup := c.level(location, user, p)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = markup
+
+	return msg
+}
+
+// level builds the menu for "" (domains), "domain" (subdomains) or "domain/subdomain" (actions),
+// domains and subdomains without commands the user can run are not shown.
+func (c *MenuCommander) level(location string, user *tgbotapi.User, p i18n.Printer) (string, tgbotapi.InlineKeyboardMarkup) {
+	parts := strings.SplitN(location, "/", 2)
+	domain, subdomain := parts[0], ""
+	if len(parts) == 2 {
+		subdomain = parts[1]
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	seen := make(map[string]bool)
+	for _, command := range c.commands.All() {
+		commandPath := command.Path
+		switch {
+		case commandPath.Domain == "" || !c.allowed(user, command):
+			continue
+		case domain == "":
+			if !seen[commandPath.Domain] {
+				seen[commandPath.Domain] = true
+				rows = append(rows, row(domainTitle(p, commandPath.Domain), callback("open", commandPath.Domain)))
+			}
+		case subdomain == "" && commandPath.Domain == domain:
+			if !seen[commandPath.Subdomain] {
+				seen[commandPath.Subdomain] = true
+				title := subdomainTitle(p, commandPath.Domain, commandPath.Subdomain)
+				rows = append(rows, row(title, callback("open", commandPath.Domain+"/"+commandPath.Subdomain)))
+			}
+		case commandPath.Domain == domain && commandPath.Subdomain == subdomain:
+			rows = append(rows, row(capitalize(p.T(command.Description)), callback("run", command.Name())))
+		}
+	}
+
+	text := p.T("menu.main")
+	switch {
+	case subdomain != "":
+		text = fmt.Sprintf("%s › %s", domainTitle(p, domain), subdomainTitle(p, domain, subdomain))
+		rows = append(rows, row(p.T("menu.back"), callback("open", domain)))
+	case domain != "":
+		text = domainTitle(p, domain)
+		rows = append(rows, row(p.T("menu.back"), callback("open", "")))
+	}
+	if len(rows) == 0 {
+		text += "\n" + p.T("menu.empty")
+	}
+
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func (c *MenuCommander) allowed(user *tgbotapi.User, command meta.Command) bool {
+	if c.roles == nil {
+		return true
+	}
+
+	return user != nil && c.roles.Role(int64(user.ID)) >= command.Role
+}
+
+func (c *MenuCommander) send(msg tgbotapi.Chattable) error {
+	if _, err := c.bot.Send(msg); err != nil {
+		log.Printf("MenuCommander: error sending reply message to chat - %v", err)
+	}
+
+	return nil
+}
+
+func callback(name, data string) string {
+	return path.CallbackPath{Domain: "menu", Subdomain: "main", CallbackName: name, CallbackData: data}.String()
+}
+
+func row(text, data string) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(text, data))
+}
+
+// domainTitle translates the domain name, unknown names are shown capitalized.
+func domainTitle(p i18n.Printer, domain string) string {
+	if title, found := p.Lookup("menu.domain." + domain); found {
+		return title
+	}
+
+	return capitalize(domain)
+}
+
+func subdomainTitle(p i18n.Printer, domain, subdomain string) string {
+	if title, found := p.Lookup("menu.subdomain." + domain + "." + subdomain); found {
+		return title
+	}
+
+	return capitalize(subdomain)
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// commandMessage synthesizes a command message of the user.
+func commandMessage(messageID int, from *tgbotapi.User, chat *tgbotapi.Chat, command string, arguments []string) *tgbotapi.Message {
+	text := "/" + command
+	if len(arguments) > 0 {
+		text += " " + strings.Join(arguments, " ")
+	}
+
+	return &tgbotapi.Message{
+		MessageID: messageID,
+		From:      from,
+		Chat:      chat,
+		Text:      text,
+		Entities:  &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command) + 1}},
+	}
+}
@@ -0,0 +1,111 @@
+package meta
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+)
+
+// Command describes a command for help texts, the Telegram menu and access checks.
+type Command struct {
+	Path path.CommandPath
+	// Description is the catalog key of the description.
+	Description string
+	// Args is the argument spec shown in usage, e.g. "<car id> <title>".
+	Args string
+	Role auth.Role
+	// PrivateOnly hides the command from the menu of group chats.
+	PrivateOnly bool
+}
+
+// Name returns the command name as typed by users without the leading slash,
+// commands without a domain like "start" are used as is.
+func (c Command) Name() string {
+	if c.Path.Domain == "" {
+		return c.Path.CommandName
+	}
+
+	return strings.TrimPrefix(c.Path.String(), "/")
+}
+
+func (c Command) Usage() string {
+	if c.Args == "" {
+		return "/" + c.Name()
+	}
+
+	return "/" + c.Name() + " " + c.Args
+}
+
+// NeedsArgs reports whether the command cannot be run without arguments.
+func (c Command) NeedsArgs() bool {
+	return strings.Contains(c.Args, "<")
+}
+
+// Help lists the commands with their usage and description.
+func Help(commands []Command, p i18n.Printer) string {
+	var b strings.Builder
+	for i, command := range commands {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(command.Usage())
+		b.WriteString(" — ")
+		b.WriteString(p.T(command.Description))
+	}
+
+	return b.String()
+}
+
+// Registry is the list of all declared commands.
+type Registry struct {
+	commands []Command
+	byName   map[string]Command
+}
+
+func NewRegistry(commands ...Command) *Registry {
+	r := &Registry{byName: make(map[string]Command, len(commands))}
+	r.Add(commands...)
+
+	return r
+}
+
+func (r *Registry) Add(commands ...Command) {
+	for _, command := range commands {
+		if _, found := r.byName[command.Name()]; !found {
+			r.commands = append(r.commands, command)
+		}
+		r.byName[command.Name()] = command
+	}
+}
+
+func (r *Registry) Lookup(name string) (Command, bool) {
+	command, found := r.byName[name]
+
+	return command, found
+}
+
+// RequiredRole is suitable for auth.Middleware.
+func (r *Registry) RequiredRole(name string) (auth.Role, bool) {
+	command, found := r.Lookup(name)
+
+	return command.Role, found
+}
+
+// All returns the commands in declaration order.
+func (r *Registry) All() []Command {
+	return append([]Command(nil), r.commands...)
+}
+
+// Names returns sorted names of all commands.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.commands))
+	for _, command := range r.commands {
+		names = append(names, command.Name())
+	}
+	sort.Strings(names)
+
+	return names
+}
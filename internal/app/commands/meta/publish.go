@@ -0,0 +1,80 @@
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+)
+
+// Requester is implemented by *tgbotapi.BotAPI, the library has no setMyCommands wrapper.
+type Requester interface {
+	MakeRequest(endpoint string, params url.Values) (tgbotapi.APIResponse, error)
+}
+
+type botCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+type commandScope struct {
+	Type string `json:"type"`
+}
+
+// Publish sets the command menus of private and group chats to the commands
+// available to users with the given role. Descriptions are published for every language
+// of the localizer, clients with other languages get the fallback one.
+func Publish(bot Requester, registry *Registry, role auth.Role, localizer *i18n.Localizer) error {
+	languages := append([]string{""}, localizer.Languages()...)
+	for _, scope := range []string{"all_private_chats", "all_group_chats"} {
+		for _, lang := range languages {
+			p := localizer.Fallback()
+			if lang != "" {
+				p = localizer.Printer(lang)
+			}
+
+			var commands []botCommand
+			for _, command := range registry.All() {
+				if command.Role > role || (command.PrivateOnly && scope != "all_private_chats") {
+					continue
+				}
+				commands = append(commands, botCommand{Command: command.Name(), Description: p.T(command.Description)})
+			}
+
+			if err := setMyCommands(bot, commands, scope, lang); err != nil {
+				return fmt.Errorf("cannot publish commands for %s %s: %w", scope, lang, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func setMyCommands(bot Requester, commands []botCommand, scope, lang string) error {
+	if commands == nil {
+		commands = []botCommand{}
+	}
+
+	encodedCommands, err := json.Marshal(commands)
+	if err != nil {
+		return err
+	}
+	encodedScope, err := json.Marshal(commandScope{Type: scope})
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{
+		"commands": {string(encodedCommands)},
+		"scope":    {string(encodedScope)},
+	}
+	if lang != "" {
+		params.Set("language_code", lang)
+	}
+	_, err = bot.MakeRequest("setMyCommands", params)
+
+	return err
+}
@@ -0,0 +1,269 @@
+package settings
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/args"
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/render"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+	userSettings "github.com/ozonmp/omp-bot/internal/app/settings"
+)
+
+// pageSizes are offered by the page size button in turn.
+var pageSizes = []uint64{3, 5, 10, 20}
+
+var sorts = []string{"id", "-id", "title"}
+
+type settingsArgs struct {
+	PageSize uint64   `arg:"page_size,min=1,max=50"`
+	Sort     string   `arg:"sort,oneof=id|-id|title"`
+	Language string   `arg:"language"`
+	Timezone string   `arg:"timezone"`
+	Notify   []string `arg:"notify,oneof=expiry|changes|none"`
+}
+
+var templates = render.New(render.ModeHTML).
+	MustAdd("settings", `{{bold (t "settings.title")}}
+{{t "settings.page_size"}}: {{.PageSize}}
+{{t "settings.language"}}: {{t (printf "language.name.%s" .Language)}}
+{{t "settings.sort"}}: {{t (printf "settings.sort.%s" .Sort)}}
+{{t "settings.timezone"}}: {{.Timezone}}
+{{range .Notifications}}{{t (printf "settings.notify.%s" .Topic)}}: {{if .On}}{{t "settings.on"}}{{else}}{{t "settings.off"}}{{end}}
+{{end}}
+{{italic (t "settings.hint")}}`)
+
+type topicView struct {
+	Topic string
+	On    bool
+}
+
+// view is the settings with defaults filled in.
+type view struct {
+	PageSize      uint64
+	Language      string
+	Sort          string
+	Timezone      string
+	Notifications []topicView
+}
+
+// SettingsCommander shows and changes preferences of the user.
+type SettingsCommander struct {
+	bot       sender.Sender
+	store     *userSettings.Store
+	localizer *i18n.Localizer
+}
+
+func NewSettingsCommander(bot sender.Sender, store *userSettings.Store, localizer *i18n.Localizer) *SettingsCommander {
+	return &SettingsCommander{bot: bot, store: store, localizer: localizer}
+}
+
+func (c *SettingsCommander) Commands() []meta.Command {
+	return []meta.Command{
+		{Path: path.CommandPath{CommandName: "settings"}, Args: args.Usage(settingsArgs{}), Description: "settings.command", Role: auth.RoleUser},
+	}
+}
+
+// HandleCommand applies the given settings, if any, and shows them.
+func (c *SettingsCommander) HandleCommand(msg *tgbotapi.Message, commandPath path.CommandPath) error {
+	if msg.From == nil {
+		return nil
+	}
+
+	var parsed settingsArgs
+	if err := args.ParseMessage(msg, &parsed); err != nil {
+		return err
+	}
+
+	usage := "/settings " + args.Usage(settingsArgs{})
+	lang := ""
+	if parsed.Language != "" {
+		if lang = c.localizer.Match(parsed.Language); lang == "" {
+			problem := i18n.M("args.one_of", "language", strings.Join(c.localizer.Languages(), ", "))
+			return cmderr.InvalidArguments(problem, usage)
+		}
+	}
+	if parsed.Timezone != "" {
+		if _, err := time.LoadLocation(parsed.Timezone); err != nil {
+			return cmderr.InvalidArguments(i18n.M("settings.bad_timezone", parsed.Timezone), usage)
+		}
+	}
+
+	err := c.store.Update(int64(msg.From.ID), func(settings *userSettings.Settings) error {
+		if parsed.PageSize != 0 {
+			settings.PageSize = parsed.PageSize
+		}
+		if parsed.Sort != "" {
+			settings.Sort = parsed.Sort
+		}
+		if lang != "" {
+			settings.Language = lang
+		}
+		if parsed.Timezone != "" {
+			settings.Timezone = parsed.Timezone
+		}
+		if parsed.Notify != nil {
+			// topics left out are turned off, not reset to their defaults
+			settings.Notifications = make(map[string]bool)
+			for _, topic := range userSettings.Topics {
+				settings.Notifications[topic] = false
+			}
+			for _, topic := range parsed.Notify {
+				if topic != "none" {
+					settings.Notifications[topic] = true
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return cmderr.Internal(err)
+	}
+
+	text, markup, err := c.card(msg.From)
+	if err != nil {
+		return err
+	}
+
+	reply := tgbotapi.NewMessage(msg.Chat.ID, text)
+	reply.ParseMode = templates.Mode()
+	reply.ReplyMarkup = markup
+	c.send(reply)
+
+	return nil
+}
+
+// HandleCallback switches the setting named in the callback data to its next value.
+func (c *SettingsCommander) HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
+	if callbackPath.CallbackName != "next" || callback.Message == nil || callback.From == nil {
+		return cmderr.UnknownCommand(callbackPath.String())
+	}
+
+	name := callbackPath.CallbackData
+	topic := strings.TrimPrefix(name, "notify.")
+	switch {
+	case name == "page_size", name == "sort", name == "language":
+	case strings.HasPrefix(name, "notify.") && contains(userSettings.Topics, topic):
+	default:
+		return cmderr.UnknownCommand(callbackPath.String())
+	}
+
+	current := c.localizer.For(callback.From).Language()
+	err := c.store.Update(int64(callback.From.ID), func(settings *userSettings.Settings) error {
+		switch name {
+		case "page_size":
+			settings.PageSize = nextPageSize(settings.PageSizeOr(userSettings.DefaultPageSize))
+		case "sort":
+			settings.Sort = next(sorts, settings.SortOr(sorts[0]))
+		case "language":
+			settings.Language = next(c.localizer.Languages(), current)
+		default:
+			if settings.Notifications == nil {
+				settings.Notifications = make(map[string]bool)
+			}
+			settings.Notifications[topic] = !settings.Notify(topic)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return cmderr.Internal(err)
+	}
+
+	text, markup, err := c.card(callback.From)
+	if err != nil {
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = templates.Mode()
+	edit.ReplyMarkup = &markup
+	c.send(edit)
+
+	return nil
+}
+
+func (c *SettingsCommander) card(user *tgbotapi.User) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	settings, err := c.store.Get(int64(user.ID))
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, cmderr.Internal(err)
+	}
+
+	p := c.localizer.For(user)
+	v := view{
+		PageSize: settings.PageSizeOr(userSettings.DefaultPageSize),
+		Language: p.Language(),
+		Sort:     settings.SortOr(sorts[0]),
+		Timezone: settings.Location().String(),
+	}
+	for _, topic := range userSettings.Topics {
+		v.Notifications = append(v.Notifications, topicView{Topic: topic, On: settings.Notify(topic)})
+	}
+
+	text, err := templates.Render("settings", p, v)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, cmderr.Internal(err)
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		row(p.T("settings.page_size"), "page_size"),
+		row(p.T("settings.language"), "language"),
+		row(p.T("settings.sort"), "sort"),
+	}
+	for _, topic := range userSettings.Topics {
+		rows = append(rows, row(p.T("settings.notify."+topic), "notify."+topic))
+	}
+
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+func (c *SettingsCommander) send(msg tgbotapi.Chattable) {
+	if _, err := c.bot.Send(msg); err != nil {
+		log.Printf("SettingsCommander: error sending reply message to chat - %v", err)
+	}
+}
+
+func row(text, setting string) []tgbotapi.InlineKeyboardButton {
+	data := path.CallbackPath{Domain: "settings", Subdomain: "user", CallbackName: "next", CallbackData: setting}
+
+	return tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(text+" ›", data.String()))
+}
+
+func nextPageSize(current uint64) uint64 {
+	for _, size := range pageSizes {
+		if size > current {
+			return size
+		}
+	}
+
+	return pageSizes[0]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// next returns the value following the current one, the first value if current is unknown.
+func next(values []string, current string) string {
+	for i, value := range values {
+		if value == current {
+			return values[(i+1)%len(values)]
+		}
+	}
+
+	return values[0]
+}
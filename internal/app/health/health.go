@@ -0,0 +1,89 @@
+package health
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// CheckFunc reports the state of a single dependency, nil means healthy.
+type CheckFunc func() error
+
+type Registry struct {
+	mu        sync.RWMutex
+	liveness  map[string]CheckFunc
+	readiness map[string]CheckFunc
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		liveness:  make(map[string]CheckFunc),
+		readiness: make(map[string]CheckFunc),
+	}
+}
+
+// AddLiveness registers a check which makes the instance restartable when failing.
+func (r *Registry) AddLiveness(name string, check CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.liveness[name] = check
+}
+
+// AddReadiness registers a check which takes the instance out of service when failing.
+func (r *Registry) AddReadiness(name string, check CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.readiness[name] = check
+}
+
+func (r *Registry) LivenessHandler() http.Handler {
+	return r.handler(func() map[string]CheckFunc { return r.liveness })
+}
+
+func (r *Registry) ReadinessHandler() http.Handler {
+	return r.handler(func() map[string]CheckFunc { return r.readiness })
+}
+
+type report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func (r *Registry) handler(checks func() map[string]CheckFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		r.mu.RLock()
+		names := make([]string, 0, len(checks()))
+		funcs := make(map[string]CheckFunc, len(checks()))
+		for name, check := range checks() {
+			names = append(names, name)
+			funcs[name] = check
+		}
+		r.mu.RUnlock()
+		sort.Strings(names)
+
+		rep := report{Status: "ok", Checks: make(map[string]string, len(names))}
+		for _, name := range names {
+			if err := funcs[name](); err != nil {
+				rep.Status = "fail"
+				rep.Checks[name] = err.Error()
+				continue
+			}
+			rep.Checks[name] = "ok"
+		}
+
+		status := http.StatusOK
+		if rep.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(rep); err != nil {
+			log.Printf("health: error writing report - %v", err)
+		}
+	})
+}
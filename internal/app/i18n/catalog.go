@@ -0,0 +1,132 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// SourceLanguage is the language used when a message is missing in the user's one.
+const SourceLanguage = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Default is the catalog of the bundled languages.
+var Default = mustLoad(locales, "locales")
+
+// entry is either a plain text or plural forms keyed by the category of the count.
+type entry struct {
+	text  string
+	forms map[string]string
+}
+
+func (e *entry) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &e.text); err == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &e.forms); err != nil {
+		return fmt.Errorf("a message should be a string or an object of plural forms: %w", err)
+	}
+	if _, found := e.forms[other]; !found {
+		return fmt.Errorf("plural forms lack the %q one", other)
+	}
+
+	return nil
+}
+
+// Catalog holds messages of all languages, a bundle per language.
+type Catalog struct {
+	bundles map[string]map[string]entry
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{bundles: make(map[string]map[string]entry)}
+}
+
+// Load reads bundles named like ru.json from the file system.
+func Load(fsys fs.FS) (*Catalog, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := NewCatalog()
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		if err := catalog.Add(strings.TrimSuffix(file, ".json"), data); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return catalog, nil
+}
+
+func mustLoad(fsys fs.FS, dir string) *Catalog {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic("i18n: " + err.Error())
+	}
+	catalog, err := Load(sub)
+	if err != nil {
+		panic("i18n: " + err.Error())
+	}
+
+	return catalog
+}
+
+// Add merges the JSON bundle of messages into the language.
+func (c *Catalog) Add(lang string, data []byte) error {
+	var messages map[string]entry
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return err
+	}
+
+	bundle, found := c.bundles[lang]
+	if !found {
+		bundle = make(map[string]entry, len(messages))
+		c.bundles[lang] = bundle
+	}
+	for key, message := range messages {
+		bundle[key] = message
+	}
+
+	return nil
+}
+
+// Languages returns the sorted languages having a bundle.
+func (c *Catalog) Languages() []string {
+	languages := make([]string, 0, len(c.bundles))
+	for lang := range c.bundles {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+func (c *Catalog) Has(lang string) bool {
+	_, found := c.bundles[lang]
+
+	return found
+}
+
+func (c *Catalog) Printer(lang string) Printer {
+	return Printer{catalog: c, lang: lang}
+}
+
+func (c *Catalog) lookup(lang, key string) (entry, bool) {
+	if message, found := c.bundles[lang][key]; found {
+		return message, true
+	}
+	message, found := c.bundles[SourceLanguage][key]
+
+	return message, found
+}
@@ -0,0 +1,90 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// LanguageStore keeps languages chosen by users explicitly.
+type LanguageStore interface {
+	Language(userID int64) (string, bool)
+	SetLanguage(userID int64, lang string) error
+}
+
+// Localizer picks the language of a user: the chosen one, then the language of the Telegram client,
+// then the fallback one.
+type Localizer struct {
+	catalog  *Catalog
+	fallback string
+	store    LanguageStore
+}
+
+func NewLocalizer(catalog *Catalog, fallback string, store LanguageStore) *Localizer {
+	if !catalog.Has(fallback) {
+		fallback = SourceLanguage
+	}
+
+	return &Localizer{catalog: catalog, fallback: fallback, store: store}
+}
+
+func (l *Localizer) For(user *tgbotapi.User) Printer {
+	if user == nil {
+		return l.catalog.Printer(l.fallback)
+	}
+
+	if lang, found := l.store.Language(int64(user.ID)); found && l.catalog.Has(lang) {
+		return l.catalog.Printer(lang)
+	}
+	if lang := l.Match(user.LanguageCode); lang != "" {
+		return l.catalog.Printer(lang)
+	}
+
+	return l.catalog.Printer(l.fallback)
+}
+
+// ForChat picks the language for messages the bot sends on its own, when there is no user at hand.
+// Private chats have the IDs of their users, so the chosen language is used for them.
+func (l *Localizer) ForChat(chatID int64) Printer {
+	if lang, found := l.store.Language(chatID); found && l.catalog.Has(lang) {
+		return l.catalog.Printer(lang)
+	}
+
+	return l.catalog.Printer(l.fallback)
+}
+
+func (l *Localizer) Printer(lang string) Printer {
+	return l.catalog.Printer(lang)
+}
+
+// Fallback returns the printer for users whose language is unknown.
+func (l *Localizer) Fallback() Printer {
+	return l.catalog.Printer(l.fallback)
+}
+
+// Match returns the supported language for an IETF tag like ru-RU or an empty string.
+func (l *Localizer) Match(tag string) string {
+	lang := strings.ToLower(tag)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "" || !l.catalog.Has(lang) {
+		return ""
+	}
+
+	return lang
+}
+
+func (l *Localizer) Languages() []string {
+	return l.catalog.Languages()
+}
+
+// SetLanguage overrides the language of the user.
+func (l *Localizer) SetLanguage(userID int64, lang string) error {
+	if !l.catalog.Has(lang) {
+		return fmt.Errorf("language %q is not supported", lang)
+	}
+
+	return l.store.SetLanguage(userID, lang)
+}
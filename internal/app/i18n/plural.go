@@ -0,0 +1,34 @@
+package i18n
+
+// Plural categories of CLDR used in bundles.
+const (
+	one   = "one"
+	few   = "few"
+	many  = "many"
+	other = "other"
+)
+
+// pluralCategory follows the CLDR rules for integer counts, languages without
+// their own rule are treated like English.
+func pluralCategory(lang string, n int64) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch lang {
+	case "ru", "uk", "be":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return one
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return few
+		default:
+			return many
+		}
+	default:
+		if n == 1 {
+			return one
+		}
+		return other
+	}
+}
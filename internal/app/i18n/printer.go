@@ -0,0 +1,92 @@
+package i18n
+
+import "fmt"
+
+// Message is a catalog key with its arguments, it is translated once the language of the user is known.
+type Message struct {
+	Key  string
+	Args []interface{}
+}
+
+func M(key string, args ...interface{}) Message {
+	return Message{Key: key, Args: args}
+}
+
+// String renders the message in the source language, e.g. for logs.
+func (m Message) String() string {
+	return Default.Printer(SourceLanguage).Message(m)
+}
+
+// Printer translates messages into one language.
+type Printer struct {
+	catalog *Catalog
+	lang    string
+}
+
+func (p Printer) Language() string {
+	return p.lang
+}
+
+// T formats the message with the arguments, plural forms are chosen by the first argument.
+// Arguments which are messages themselves are translated too. Missing keys are returned as is.
+func (p Printer) T(key string, args ...interface{}) string {
+	text, _ := p.Lookup(key, args...)
+
+	return text
+}
+
+// Lookup is like T and also reports whether the key is known.
+func (p Printer) Lookup(key string, args ...interface{}) (string, bool) {
+	message, found := p.catalog.lookup(p.lang, key)
+	if !found {
+		return key, false
+	}
+
+	format := message.text
+	if message.forms != nil {
+		format = message.forms[other]
+		if len(args) > 0 {
+			if n, ok := count(args[0]); ok {
+				if form, found := message.forms[pluralCategory(p.lang, n)]; found {
+					format = form
+				}
+			}
+		}
+	}
+	if len(args) == 0 {
+		return format, true
+	}
+
+	translated := make([]interface{}, len(args))
+	for i, arg := range args {
+		if nested, ok := arg.(Message); ok {
+			arg = p.Message(nested)
+		}
+		translated[i] = arg
+	}
+
+	return fmt.Sprintf(format, translated...), true
+}
+
+func (p Printer) Message(m Message) string {
+	return p.T(m.Key, m.Args...)
+}
+
+func count(arg interface{}) (int64, bool) {
+	switch n := arg.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case int32:
+		return int64(n), true
+	case uint:
+		return int64(n), true
+	case uint64:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
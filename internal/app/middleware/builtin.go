@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+)
+
+var updateMetrics = expvar.NewMap("updates")
+
+// Recover turns panics of the next handlers into internal errors.
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(update tgbotapi.Update) (err error) {
+			defer func() {
+				if panicValue := recover(); panicValue != nil {
+					log.Printf("recovered from panic: %v\n%v", panicValue, string(debug.Stack()))
+					err = cmderr.Internal(fmt.Errorf("panic: %v", panicValue))
+				}
+			}()
+
+			return next.HandleUpdate(update)
+		})
+	}
+}
+
+// Logging writes a line per handled update.
+func Logging() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(update tgbotapi.Update) error {
+			start := time.Now()
+			err := next.HandleUpdate(update)
+
+			if err != nil {
+				log.Printf("update %d %s failed in %v - %v", update.UpdateID, Describe(update), time.Since(start), err)
+			} else {
+				log.Printf("update %d %s handled in %v", update.UpdateID, Describe(update), time.Since(start))
+			}
+
+			return err
+		})
+	}
+}
+
+// Metrics counts handled updates and their total duration.
+func Metrics() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(update tgbotapi.Update) error {
+			start := time.Now()
+			err := next.HandleUpdate(update)
+
+			updateMetrics.Add("total", 1)
+			updateMetrics.AddFloat("duration_seconds_sum", time.Since(start).Seconds())
+			if err != nil {
+				updateMetrics.Add("failed", 1)
+			}
+
+			return err
+		})
+	}
+}
+
+// Describe returns a short human readable description of the update source.
+func Describe(update tgbotapi.Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		return fmt.Sprintf("callback %q from %s", update.CallbackQuery.Data, userName(update.CallbackQuery.From))
+	case update.Message != nil:
+		return fmt.Sprintf("message %q from %s", update.Message.Text, userName(update.Message.From))
+	default:
+		return "unsupported update"
+	}
+}
+
+func userName(user *tgbotapi.User) string {
+	if user == nil {
+		return "unknown"
+	}
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+
+	return fmt.Sprintf("user %d", user.ID)
+}
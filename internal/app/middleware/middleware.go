@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Handler processes a single update, the returned error is rendered to the user by the router.
+type Handler interface {
+	HandleUpdate(update tgbotapi.Update) error
+}
+
+type HandlerFunc func(update tgbotapi.Update) error
+
+func (f HandlerFunc) HandleUpdate(update tgbotapi.Update) error {
+	return f(update)
+}
+
+// Middleware decorates a handler with a cross-cutting concern.
+type Middleware func(next Handler) Handler
+
+// Chain wraps h so that the first middleware is the outermost one.
+func Chain(h Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	return h
+}
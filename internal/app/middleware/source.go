@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+)
+
+// Source extracts the command name and the sender of the update.
+// Callbacks are attributed to the command with the same name, e.g. list__insurance__car.
+func Source(update tgbotapi.Update) (command string, userID, chatID int64, ok bool) {
+	switch {
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		callbackPath, err := path.ParseCallback(update.CallbackQuery.Data)
+		if err == nil {
+			command = callbackPath.Command()
+		}
+
+		return command, int64(update.CallbackQuery.From.ID), update.CallbackQuery.Message.Chat.ID, true
+	case update.Message != nil && update.Message.From != nil:
+		return update.Message.Command(), int64(update.Message.From.ID), update.Message.Chat.ID, true
+	default:
+		return "", 0, 0, false
+	}
+}
@@ -0,0 +1,42 @@
+package path
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Aliases maps short command names to full ones, e.g. "cars" to "list__insurance__car".
+type Aliases map[string]string
+
+// ParseAliases parses comma separated "<alias>=<command>" pairs.
+func ParseAliases(s string) (Aliases, error) {
+	aliases := make(Aliases)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		eq := strings.IndexByte(pair, '=')
+		if eq <= 0 || eq == len(pair)-1 {
+			return nil, fmt.Errorf("alias %q should look like <alias>=<command>", pair)
+		}
+		alias := strings.TrimPrefix(strings.TrimSpace(pair[:eq]), "/")
+		command := strings.TrimPrefix(strings.TrimSpace(pair[eq+1:]), "/")
+		if _, err := ParseCommand(command); err != nil {
+			return nil, fmt.Errorf("alias %q points to malformed command %q", alias, command)
+		}
+		aliases[strings.ToLower(alias)] = command
+	}
+
+	return aliases, nil
+}
+
+// Resolve returns the full command for an alias or the command itself.
+func (a Aliases) Resolve(command string) string {
+	if full, found := a[strings.ToLower(command)]; found {
+		return full
+	}
+
+	return command
+}
@@ -32,3 +32,8 @@ func ParseCallback(callbackData string) (CallbackPath, error) {
 func (p CallbackPath) String() string {
 	return fmt.Sprintf("%s__%s__%s__%s", p.Domain, p.Subdomain, p.CallbackName, p.CallbackData)
 }
+
+// Command returns the name of the command the callback belongs to, e.g. list__insurance__car.
+func (p CallbackPath) Command() string {
+	return fmt.Sprintf("%s__%s__%s", p.CallbackName, p.Domain, p.Subdomain)
+}
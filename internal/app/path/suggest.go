@@ -0,0 +1,81 @@
+package path
+
+import (
+	"sort"
+	"strings"
+)
+
+const maxSuggestions = 3
+
+// Suggest returns the known commands closest to the mistyped one,
+// a bare command name like "list" suggests all commands with that name.
+func Suggest(command string, known []string) []string {
+	command = strings.ToLower(command)
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	limit := len(command)/4 + 2
+
+	for _, name := range known {
+		switch {
+		case strings.HasPrefix(name, command+"__"):
+			candidates = append(candidates, candidate{name, 0})
+		default:
+			if d := distance(command, name); d <= limit {
+				candidates = append(candidates, candidate{name, d})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+
+	return suggestions
+}
+
+// distance is the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minOf(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func minOf(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
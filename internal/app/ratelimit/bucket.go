@@ -0,0 +1,178 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rule describes a token bucket: Burst tokens at most, refilled at Rate tokens per second.
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseRule parses rules like "2/s:5" or "20/m", burst defaults to the number of events.
+func ParseRule(s string) (Rule, error) {
+	ratePart, burstPart := s, ""
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		ratePart, burstPart = s[:i], s[i+1:]
+	}
+
+	slash := strings.IndexByte(ratePart, '/')
+	if slash < 0 {
+		return Rule{}, fmt.Errorf("rate %q should look like <events>/<s|m|h>", ratePart)
+	}
+	events, err := strconv.Atoi(ratePart[:slash])
+	if err != nil || events <= 0 {
+		return Rule{}, fmt.Errorf("rate %q has wrong number of events", ratePart)
+	}
+
+	var per time.Duration
+	switch ratePart[slash+1:] {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return Rule{}, fmt.Errorf("rate %q has unknown period", ratePart)
+	}
+
+	rule := Rule{Rate: float64(events) / per.Seconds(), Burst: events}
+	if burstPart != "" {
+		rule.Burst, err = strconv.Atoi(burstPart)
+		if err != nil || rule.Burst <= 0 {
+			return Rule{}, fmt.Errorf("burst %q should be a positive number", burstPart)
+		}
+	}
+
+	return rule, nil
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) refill(rule Rule, now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * rule.Rate
+	if b.tokens > float64(rule.Burst) {
+		b.tokens = float64(rule.Burst)
+	}
+	b.last = now
+}
+
+// ParseRules parses comma separated "<name>=<rule>" pairs.
+func ParseRules(s string) (map[string]Rule, error) {
+	rules := make(map[string]Rule)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		eq := strings.IndexByte(pair, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("rule %q should look like <name>=<rule>", pair)
+		}
+		rule, err := ParseRule(pair[eq+1:])
+		if err != nil {
+			return nil, err
+		}
+		rules[strings.TrimSpace(pair[:eq])] = rule
+	}
+
+	return rules, nil
+}
+
+// Limiter keeps a token bucket per key.
+type Limiter struct {
+	rule Rule
+	now  func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// maxIdleBuckets is the size after which full buckets are forgotten.
+const maxIdleBuckets = 10000
+
+func NewLimiter(rule Rule) *Limiter {
+	return &Limiter{
+		rule:    rule,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *Limiter) get(key string, now time.Time) *bucket {
+	b, found := l.buckets[key]
+	if !found {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.sweep(now)
+		}
+		b = &bucket{tokens: float64(l.rule.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.refill(l.rule, now)
+
+	return b
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		b.refill(l.rule, now)
+		if b.tokens >= float64(l.rule.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Allow takes a token if there is one, otherwise it returns the time until the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.get(key, l.now())
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	return false, l.delay(b)
+}
+
+// Peek reports whether Allow would take a token without taking it.
+func (l *Limiter) Peek(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.get(key, l.now())
+	if b.tokens >= 1 {
+		return true, 0
+	}
+
+	return false, l.delay(b)
+}
+
+// Reserve takes a token unconditionally and returns how long the caller has to wait before using it.
+func (l *Limiter) Reserve(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.get(key, l.now())
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / l.rule.Rate * float64(time.Second))
+}
+
+func (l *Limiter) delay(b *bucket) time.Duration {
+	return time.Duration((1 - b.tokens) / l.rule.Rate * float64(time.Second))
+}
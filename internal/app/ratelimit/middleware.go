@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/middleware"
+)
+
+// DefaultRule is the name of the rule applied to commands without their own one.
+const DefaultRule = "default"
+
+// Config holds rules by command name, e.g. "list__insurance__car".
+// Callbacks are limited by the rule of the command with the same name.
+type Config struct {
+	User map[string]Rule
+	Chat map[string]Rule
+}
+
+type inbound struct {
+	config Config
+
+	// allowMu makes checking and taking the tokens of all scopes one step
+	allowMu sync.Mutex
+
+	mu       sync.Mutex
+	limiters map[string]*Limiter
+	notified map[string]time.Time
+}
+
+// Middleware drops updates exceeding per user or per chat limits,
+// the user is told about throttling once per throttled period.
+func Middleware(config Config) middleware.Middleware {
+	l := &inbound{
+		config:   config,
+		limiters: make(map[string]*Limiter),
+		notified: make(map[string]time.Time),
+	}
+
+	return func(next middleware.Handler) middleware.Handler {
+		return middleware.HandlerFunc(func(update tgbotapi.Update) error {
+			command, userID, chatID, ok := middleware.Source(update)
+			if !ok {
+				return next.HandleUpdate(update)
+			}
+
+			if key, wait, allowed := l.allow(command, userID, chatID); !allowed {
+				return l.throttled(key+":"+command, wait)
+			}
+
+			return next.HandleUpdate(update)
+		})
+	}
+}
+
+// allow takes a token from the buckets of the user and the chat only if both have one,
+// so an update rejected by one limit is not charged to the other. It returns the key of the exhausted bucket.
+func (l *inbound) allow(command string, userID, chatID int64) (string, time.Duration, bool) {
+	type check struct {
+		key     string
+		limiter *Limiter
+	}
+
+	var checks []check
+	for _, scope := range []struct {
+		name  string
+		id    int64
+		rules map[string]Rule
+	}{
+		{"user", userID, l.config.User},
+		{"chat", chatID, l.config.Chat},
+	} {
+		if limiter := l.limiter(scope.name, command, scope.rules); limiter != nil {
+			checks = append(checks, check{key: fmt.Sprintf("%s:%d", scope.name, scope.id), limiter: limiter})
+		}
+	}
+
+	l.allowMu.Lock()
+	defer l.allowMu.Unlock()
+
+	for _, c := range checks {
+		if allowed, wait := c.limiter.Peek(c.key); !allowed {
+			return c.key, wait, false
+		}
+	}
+	for _, c := range checks {
+		c.limiter.Allow(c.key)
+	}
+
+	return "", 0, true
+}
+
+func (l *inbound) limiter(scope, command string, rules map[string]Rule) *Limiter {
+	rule, found := rules[command]
+	name := scope + ":" + command
+	if !found {
+		rule, found = rules[DefaultRule]
+		name = scope + ":" + DefaultRule
+	}
+	if !found {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limiter, found := l.limiters[name]
+	if !found {
+		limiter = NewLimiter(rule)
+		l.limiters[name] = limiter
+	}
+
+	return limiter
+}
+
+func (l *inbound) throttled(key string, wait time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if until, found := l.notified[key]; found && now.Before(until) {
+		return nil
+	}
+
+	if len(l.notified) >= maxIdleBuckets {
+		for k, until := range l.notified {
+			if now.After(until) {
+				delete(l.notified, k)
+			}
+		}
+	}
+	l.notified[key] = now.Add(wait)
+
+	return cmderr.RateLimited(wait)
+}
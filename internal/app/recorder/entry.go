@@ -0,0 +1,84 @@
+package recorder
+
+import (
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	KindUpdate = "update"
+	KindSend   = "send"
+)
+
+// Entry is a line of a recording.
+type Entry struct {
+	Time   time.Time        `json:"time"`
+	Kind   string           `json:"kind"`
+	Update *tgbotapi.Update `json:"update,omitempty"`
+	Output *Output          `json:"output,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// Output is the comparable part of a request sent to Telegram.
+type Output struct {
+	Method    string     `json:"method"`
+	ChatID    int64      `json:"chat_id"`
+	MessageID int        `json:"message_id,omitempty"`
+	Text      string     `json:"text,omitempty"`
+	ParseMode string     `json:"parse_mode,omitempty"`
+	Buttons   [][]string `json:"buttons,omitempty"`
+}
+
+// OutputOf describes the supported chattables, others are recorded with their Go type only.
+func OutputOf(c tgbotapi.Chattable) Output {
+	switch config := c.(type) {
+	case tgbotapi.MessageConfig:
+		return Output{
+			Method:    "sendMessage",
+			ChatID:    config.ChatID,
+			Text:      config.Text,
+			ParseMode: config.ParseMode,
+			Buttons:   buttons(config.ReplyMarkup),
+		}
+	case tgbotapi.EditMessageTextConfig:
+		var markup interface{}
+		if config.ReplyMarkup != nil {
+			markup = *config.ReplyMarkup
+		}
+		return Output{
+			Method:    "editMessageText",
+			ChatID:    config.ChatID,
+			MessageID: config.MessageID,
+			Text:      config.Text,
+			ParseMode: config.ParseMode,
+			Buttons:   buttons(markup),
+		}
+	case tgbotapi.DocumentConfig:
+		return Output{
+			Method: "sendDocument",
+			ChatID: config.ChatID,
+			Text:   config.Caption,
+		}
+	default:
+		return Output{Method: typeName(c)}
+	}
+}
+
+func buttons(markup interface{}) [][]string {
+	keyboard, ok := markup.(tgbotapi.InlineKeyboardMarkup)
+	if !ok {
+		return nil
+	}
+
+	rows := make([][]string, 0, len(keyboard.InlineKeyboard))
+	for _, row := range keyboard.InlineKeyboard {
+		texts := make([]string, 0, len(row))
+		for _, button := range row {
+			texts = append(texts, button.Text)
+		}
+		rows = append(rows, texts)
+	}
+
+	return rows
+}
@@ -0,0 +1,156 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/middleware"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+)
+
+// Recorder appends incoming updates and outgoing messages to a JSONL file,
+// the file is rotated to <path>.1 ... <path>.<backups> when it exceeds maxBytes.
+type Recorder struct {
+	path     string
+	maxBytes int64
+	backups  int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func New(path string, maxBytes int64, backups int) (*Recorder, error) {
+	r := &Recorder{path: path, maxBytes: maxBytes, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Recorder) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot open recording %s: %w", r.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("cannot open recording %s: %w", r.path, err)
+	}
+
+	r.file, r.size = file, info.Size()
+
+	return nil
+}
+
+func (r *Recorder) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	for i := r.backups - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", r.path, i)
+		if _, err := os.Stat(from); err == nil {
+			if err := os.Rename(from, fmt.Sprintf("%s.%d", r.path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	if r.backups > 0 {
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+
+	return r.open()
+}
+
+func (r *Recorder) write(entry Entry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Recorder: cannot encode %s entry - %v", entry.Kind, err)
+		return
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(line)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			log.Printf("Recorder: cannot rotate %s - %v", r.path, err)
+			return
+		}
+	}
+
+	n, err := r.file.Write(line)
+	r.size += int64(n)
+	if err != nil {
+		log.Printf("Recorder: cannot write to %s - %v", r.path, err)
+	}
+}
+
+func (r *Recorder) RecordUpdate(update tgbotapi.Update) {
+	redactedUpdate := Redact(update)
+	r.write(Entry{Time: time.Now(), Kind: KindUpdate, Update: &redactedUpdate})
+}
+
+func (r *Recorder) RecordSend(c tgbotapi.Chattable, err error) {
+	output := OutputOf(c)
+	output.Text = RedactText(output.Text)
+
+	entry := Entry{Time: time.Now(), Kind: KindSend, Output: &output}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	r.write(entry)
+}
+
+// Middleware records every update before it is handled.
+func (r *Recorder) Middleware() middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return middleware.HandlerFunc(func(update tgbotapi.Update) error {
+			r.RecordUpdate(update)
+
+			return next.HandleUpdate(update)
+		})
+	}
+}
+
+// Sender records every message sent through next.
+func (r *Recorder) Sender(next sender.Sender) sender.Sender {
+	return recordingSender{recorder: r, next: next}
+}
+
+type recordingSender struct {
+	recorder *Recorder
+	next     sender.Sender
+}
+
+func (s recordingSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	msg, err := s.next.Send(c)
+	s.recorder.RecordSend(c, err)
+
+	return msg, err
+}
+
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+func typeName(v interface{}) string {
+	return reflect.TypeOf(v).String()
+}
@@ -0,0 +1,76 @@
+package recorder
+
+import (
+	"regexp"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const redacted = "[redacted]"
+
+var (
+	emailPattern = regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.-]+`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s()-]{8,}\d`)
+)
+
+// Redact removes personal data from the update keeping everything the router relies on:
+// IDs, chat types, command text and callback data.
+func Redact(update tgbotapi.Update) tgbotapi.Update {
+	if update.Message != nil {
+		update.Message = redactMessage(update.Message)
+	}
+	if update.EditedMessage != nil {
+		update.EditedMessage = redactMessage(update.EditedMessage)
+	}
+	if update.CallbackQuery != nil {
+		callback := *update.CallbackQuery
+		callback.From = redactUser(callback.From)
+		if callback.Message != nil {
+			callback.Message = redactMessage(callback.Message)
+		}
+		update.CallbackQuery = &callback
+	}
+
+	return update
+}
+
+// RedactText hides e-mails and phone numbers.
+func RedactText(text string) string {
+	text = emailPattern.ReplaceAllString(text, redacted)
+
+	return phonePattern.ReplaceAllString(text, redacted)
+}
+
+func redactMessage(msg *tgbotapi.Message) *tgbotapi.Message {
+	copied := *msg
+	copied.From = redactUser(copied.From)
+	copied.ForwardFrom = redactUser(copied.ForwardFrom)
+	copied.Text = RedactText(copied.Text)
+	copied.Caption = RedactText(copied.Caption)
+	copied.Contact = nil
+	copied.Location = nil
+	copied.Venue = nil
+	if copied.Chat != nil {
+		chat := *copied.Chat
+		chat.UserName, chat.FirstName, chat.LastName = "", "", ""
+		copied.Chat = &chat
+	}
+	if copied.ReplyToMessage != nil {
+		copied.ReplyToMessage = redactMessage(copied.ReplyToMessage)
+	}
+
+	return &copied
+}
+
+func redactUser(user *tgbotapi.User) *tgbotapi.User {
+	if user == nil {
+		return nil
+	}
+
+	return &tgbotapi.User{
+		ID:           user.ID,
+		FirstName:    redacted,
+		LanguageCode: user.LanguageCode,
+		IsBot:        user.IsBot,
+	}
+}
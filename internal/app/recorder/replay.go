@@ -0,0 +1,109 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Step is a recorded update with the messages sent while it was handled.
+// Steps are only reliable for recordings made with a single update worker.
+type Step struct {
+	Line    int
+	Update  tgbotapi.Update
+	Outputs []Output
+}
+
+// ReadSteps groups a recording by updates, sends recorded before the first update are skipped.
+func ReadSteps(r io.Reader) ([]Step, error) {
+	var steps []Step
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		switch {
+		case entry.Kind == KindUpdate && entry.Update != nil:
+			steps = append(steps, Step{Line: line, Update: *entry.Update})
+		case entry.Kind == KindSend && entry.Output != nil && len(steps) > 0:
+			last := &steps[len(steps)-1]
+			last.Outputs = append(last.Outputs, *entry.Output)
+		}
+	}
+
+	return steps, scanner.Err()
+}
+
+// Collector is a fake sender remembering everything sent through it.
+type Collector struct {
+	mu      sync.Mutex
+	lastID  int
+	outputs []Output
+}
+
+func (c *Collector) Send(chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	output := OutputOf(chattable)
+	output.Text = RedactText(output.Text)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.outputs = append(c.outputs, output)
+	c.lastID++
+
+	return tgbotapi.Message{
+		MessageID: c.lastID,
+		Chat:      &tgbotapi.Chat{ID: output.ChatID},
+		Text:      output.Text,
+	}, nil
+}
+
+// Take returns the collected outputs and forgets them.
+func (c *Collector) Take() []Output {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	outputs := c.outputs
+	c.outputs = nil
+
+	return outputs
+}
+
+// Diff describes the differences between recorded and replayed outputs, message IDs are not compared.
+func Diff(recorded, replayed []Output) []string {
+	var diffs []string
+	for i := 0; i < len(recorded) || i < len(replayed); i++ {
+		switch {
+		case i >= len(replayed):
+			diffs = append(diffs, fmt.Sprintf("- %s", describe(recorded[i])))
+		case i >= len(recorded):
+			diffs = append(diffs, fmt.Sprintf("+ %s", describe(replayed[i])))
+		default:
+			want, got := recorded[i], replayed[i]
+			want.MessageID, got.MessageID = 0, 0
+			if !reflect.DeepEqual(want, got) {
+				diffs = append(diffs, fmt.Sprintf("- %s", describe(recorded[i])), fmt.Sprintf("+ %s", describe(replayed[i])))
+			}
+		}
+	}
+
+	return diffs
+}
+
+func describe(o Output) string {
+	s := fmt.Sprintf("%s chat=%d %q", o.Method, o.ChatID, o.Text)
+	if len(o.Buttons) > 0 {
+		s += fmt.Sprintf(" buttons=%v", o.Buttons)
+	}
+
+	return s
+}
@@ -0,0 +1,222 @@
+package reminder
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/render"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+	userSettings "github.com/ozonmp/omp-bot/internal/app/settings"
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+	policyService "github.com/ozonmp/omp-bot/internal/service/insurance/policy"
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+// sentBucket keeps the reminders already sent, keyed by policy, end date and chat.
+const sentBucket = "reminder.expiry"
+
+// scanPageSize is the number of policies read at once.
+const scanPageSize = 100
+
+type sent struct {
+	End    time.Time `json:"end"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+type recipient struct {
+	chatID   int64
+	location *time.Location
+	printer  i18n.Printer
+}
+
+// ExpiryReminder tells users subscribed to expiry notifications and the configured chats
+// about active policies ending within the given number of days. Each reminder is sent once
+// for the end date of the policy, so a renewed policy is reminded about again.
+type ExpiryReminder struct {
+	bot       sender.Sender
+	policies  policyService.PolicyService
+	cars      carService.CarService
+	settings  *userSettings.Store
+	localizer *i18n.Localizer
+	store     storage.Store
+	within    int
+	chats     []int64
+}
+
+func NewExpiryReminder(
+	bot sender.Sender,
+	policies policyService.PolicyService,
+	cars carService.CarService,
+	settings *userSettings.Store,
+	localizer *i18n.Localizer,
+	store storage.Store,
+	within int,
+	chats []int64,
+) *ExpiryReminder {
+	return &ExpiryReminder{
+		bot:       bot,
+		policies:  policies,
+		cars:      cars,
+		settings:  settings,
+		localizer: localizer,
+		store:     store,
+		within:    within,
+		chats:     chats,
+	}
+}
+
+// Run sends the reminders due at now, failed ones are retried by the next run.
+func (r *ExpiryReminder) Run(now time.Time) error {
+	expiring, err := r.expiring(now)
+	if err != nil {
+		return err
+	}
+
+	recipients, err := r.recipients()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, policy := range expiring {
+		for _, to := range recipients {
+			days := daysLeft(policy.End, now.In(to.location))
+			if days < 0 || days > r.within {
+				continue
+			}
+			if err := r.remind(policy, to, days, now); err != nil {
+				failed++
+				log.Printf("ExpiryReminder: cannot remind chat %d of policy %d - %v", to.chatID, policy.ID, err)
+			}
+		}
+	}
+
+	if err := r.prune(now); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d reminders failed", failed)
+	}
+
+	return nil
+}
+
+// expiring returns active policies which may end within the period in any time zone.
+func (r *ExpiryReminder) expiring(now time.Time) ([]insurance.Policy, error) {
+	var expiring []insurance.Policy
+	for cursor := uint64(0); ; cursor += scanPageSize {
+		policies, err := r.policies.List(cursor, scanPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			days := daysLeft(policy.End, now.UTC())
+			if policy.Status == insurance.PolicyActive && days >= -1 && days <= r.within+1 {
+				expiring = append(expiring, policy)
+			}
+		}
+		if len(policies) < scanPageSize {
+			return expiring, nil
+		}
+	}
+}
+
+func (r *ExpiryReminder) recipients() ([]recipient, error) {
+	userIDs, err := r.settings.Subscribers(userSettings.NotifyExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := make([]recipient, 0, len(userIDs)+len(r.chats))
+	for _, userID := range userIDs {
+		settings, err := r.settings.Get(userID)
+		if err != nil {
+			return nil, err
+		}
+		// the private chat of a user has the ID of the user
+		recipients = append(recipients, recipient{chatID: userID, location: settings.Location(), printer: r.localizer.ForChat(userID)})
+	}
+	for _, chatID := range r.chats {
+		recipients = append(recipients, recipient{chatID: chatID, location: time.UTC, printer: r.localizer.ForChat(chatID)})
+	}
+
+	return recipients, nil
+}
+
+func (r *ExpiryReminder) remind(policy insurance.Policy, to recipient, days int, now time.Time) error {
+	key := sentKey(policy, to.chatID)
+
+	var found bool
+	err := r.store.View(func(tx storage.Tx) (err error) {
+		found, err = tx.Get(sentBucket, key, &sent{})
+		return err
+	})
+	if err != nil || found {
+		return err
+	}
+
+	text := to.printer.T("reminder.expiry", days, policy.ID, r.carTitles(policy), policy.End.Format(render.DateLayout))
+	if _, err := r.bot.Send(tgbotapi.NewMessage(to.chatID, text)); err != nil {
+		return err
+	}
+
+	return r.store.Update(func(tx storage.Tx) error {
+		return tx.Put(sentBucket, key, sent{End: policy.End, SentAt: now})
+	})
+}
+
+// prune forgets reminders of periods which have ended.
+func (r *ExpiryReminder) prune(now time.Time) error {
+	return r.store.Update(func(tx storage.Tx) error {
+		keys, err := tx.Keys(sentBucket)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			var s sent
+			if _, err := tx.Get(sentBucket, key, &s); err != nil {
+				return err
+			}
+			if daysLeft(s.End, now.UTC()) < -1 {
+				if err := tx.Delete(sentBucket, key); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *ExpiryReminder) carTitles(policy insurance.Policy) string {
+	titles := make([]string, 0, len(policy.CarIDs))
+	for _, id := range policy.CarIDs {
+		car, err := r.cars.Describe(id)
+		if err != nil {
+			titles = append(titles, fmt.Sprintf("#%d", id))
+			continue
+		}
+		titles = append(titles, fmt.Sprintf("%s (#%d)", car.Title, id))
+	}
+
+	return strings.Join(titles, ", ")
+}
+
+func sentKey(policy insurance.Policy, chatID int64) string {
+	return fmt.Sprintf("%020d/%s/%d", policy.ID, policy.End.Format(render.DateLayout), chatID)
+}
+
+// daysLeft returns the number of days from the date of now to the end date, the end date is stored in UTC.
+func daysLeft(end time.Time, now time.Time) int {
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	year, month, day = end.Date()
+	last := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	return int(last.Sub(today).Hours() / 24)
+}
@@ -0,0 +1,107 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Parse modes of the Bot API, an empty mode sends plain text.
+const (
+	ModePlain      = ""
+	ModeHTML       = tgbotapi.ModeHTML
+	ModeMarkdownV2 = "MarkdownV2"
+)
+
+// Markup is text already formatted for the parse mode, it is not escaped again.
+type Markup string
+
+var (
+	htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+	markdownV2Escaper = newBackslashEscaper("\\_*[]()~`>#+-=|{}.!")
+	// markdownV2CodeEscaper is used inside code spans and pre blocks.
+	markdownV2CodeEscaper = newBackslashEscaper("\\`")
+	// markdownV2URLEscaper is used inside the (...) part of links.
+	markdownV2URLEscaper = newBackslashEscaper("\\)")
+)
+
+func newBackslashEscaper(special string) *strings.Replacer {
+	pairs := make([]string, 0, 2*len(special))
+	for _, r := range special {
+		pairs = append(pairs, string(r), `\`+string(r))
+	}
+
+	return strings.NewReplacer(pairs...)
+}
+
+// Escape makes the text safe to send in the parse mode.
+func Escape(mode, text string) string {
+	switch mode {
+	case ModeHTML:
+		return htmlEscaper.Replace(text)
+	case ModeMarkdownV2:
+		return markdownV2Escaper.Replace(text)
+	default:
+		return text
+	}
+}
+
+// markup implements formatting functions of templates for one parse mode.
+type markup struct {
+	mode string
+}
+
+// escape is appended to every action of templates, values which are Markup are left as is.
+func (m markup) escape(v interface{}) Markup {
+	if safe, ok := v.(Markup); ok {
+		return safe
+	}
+
+	return Markup(Escape(m.mode, fmt.Sprint(v)))
+}
+
+func (m markup) bold(v interface{}) Markup {
+	return m.wrap(v, "<b>", "</b>", "*", "*")
+}
+
+func (m markup) italic(v interface{}) Markup {
+	return m.wrap(v, "<i>", "</i>", "_", "_")
+}
+
+func (m markup) code(v interface{}) Markup {
+	text := fmt.Sprint(v)
+	switch m.mode {
+	case ModeHTML:
+		return Markup("<code>" + htmlEscaper.Replace(text) + "</code>")
+	case ModeMarkdownV2:
+		return Markup("`" + markdownV2CodeEscaper.Replace(text) + "`")
+	default:
+		return Markup(text)
+	}
+}
+
+func (m markup) link(url string, v interface{}) Markup {
+	text := m.escape(v)
+	switch m.mode {
+	case ModeHTML:
+		return Markup(`<a href="` + htmlEscaper.Replace(url) + `">` + string(text) + "</a>")
+	case ModeMarkdownV2:
+		return Markup("[" + string(text) + "](" + markdownV2URLEscaper.Replace(url) + ")")
+	default:
+		return Markup(string(text) + " (" + url + ")")
+	}
+}
+
+func (m markup) wrap(v interface{}, htmlOpen, htmlClose, markdownOpen, markdownClose string) Markup {
+	text := string(m.escape(v))
+	switch m.mode {
+	case ModeHTML:
+		return Markup(htmlOpen + text + htmlClose)
+	case ModeMarkdownV2:
+		return Markup(markdownOpen + text + markdownClose)
+	default:
+		return Markup(text)
+	}
+}
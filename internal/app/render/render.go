@@ -0,0 +1,146 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+)
+
+const escapeFunc = "_escape"
+
+// DateLayout is used by the date function of templates.
+const DateLayout = "2006-01-02"
+
+// Renderer formats entities with templates for one parse mode.
+//
+// Templates are text/template ones with the literal text and the output of every action escaped
+// for the parse mode, so user supplied values never break the markup. Formatting is done with functions:
+//
+//	{{bold (t "car.card.title" .ID)}}
+//	{{t "car.card.name"}}: {{.Title}}
+//
+// Available functions are t (translation to the language of the reader), bold, italic, code, link and date.
+type Renderer struct {
+	mode      string
+	templates *template.Template
+	// escaped keeps trees already processed by escapeNode, Templates returns the whole set
+	escaped map[*parse.Tree]bool
+}
+
+func New(mode string) *Renderer {
+	m := markup{mode: mode}
+
+	return &Renderer{
+		mode:    mode,
+		escaped: make(map[*parse.Tree]bool),
+		templates: template.New("").Funcs(template.FuncMap{
+			escapeFunc: m.escape,
+			"bold":     m.bold,
+			"italic":   m.italic,
+			"code":     m.code,
+			"link":     m.link,
+			"date":     func(t time.Time) string { return t.Format(DateLayout) },
+			// t is bound to the printer of the reader when rendering
+			"t": func(key string, args ...interface{}) string { return key },
+		}),
+	}
+}
+
+func (r *Renderer) Mode() string {
+	return r.mode
+}
+
+// Add parses the template under the name, it may define nested templates.
+func (r *Renderer) Add(name, text string) error {
+	tmpl, err := r.templates.New(name).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && !r.escaped[t.Tree] {
+			r.escaped[t.Tree] = true
+			r.escapeNode(t.Tree, t.Tree.Root)
+		}
+	}
+
+	return nil
+}
+
+// MustAdd is like Add but panics on malformed templates, it is meant for templates built into the code.
+func (r *Renderer) MustAdd(name, text string) *Renderer {
+	if err := r.Add(name, text); err != nil {
+		panic(fmt.Sprintf("render: template %s: %v", name, err))
+	}
+
+	return r
+}
+
+// Render executes the template with the data, texts are translated with the printer.
+func (r *Renderer) Render(name string, p i18n.Printer, data interface{}) (string, error) {
+	templates, err := r.templates.Clone()
+	if err != nil {
+		return "", err
+	}
+	templates.Funcs(template.FuncMap{"t": p.T})
+
+	var b strings.Builder
+	if err := templates.ExecuteTemplate(&b, name, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// Message renders the template into a message to the chat with the parse mode set.
+func (r *Renderer) Message(chatID int64, name string, p i18n.Printer, data interface{}) (tgbotapi.MessageConfig, error) {
+	text, err := r.Render(name, p, data)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = r.mode
+
+	return msg, nil
+}
+
+// escapeNode escapes literal text and appends the escape function to the pipeline of every action
+// printing a value, like html/template does.
+func (r *Renderer) escapeNode(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			r.escapeNode(tree, child)
+		}
+	case *parse.TextNode:
+		n.Text = []byte(Escape(r.mode, string(n.Text)))
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		escape := parse.NewIdentifier(escapeFunc).SetTree(tree).SetPos(n.Pos)
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{escape},
+		})
+	case *parse.IfNode:
+		r.escapeNode(tree, n.List)
+		r.escapeNode(tree, n.ElseList)
+	case *parse.RangeNode:
+		r.escapeNode(tree, n.List)
+		r.escapeNode(tree, n.ElseList)
+	case *parse.WithNode:
+		r.escapeNode(tree, n.List)
+		r.escapeNode(tree, n.ElseList)
+	}
+}
@@ -0,0 +1,98 @@
+package router
+
+import (
+	"expvar"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+)
+
+const commandFormat = "/{command}__{domain}__{subdomain}"
+
+// commandErrors counts rendered errors by kind.
+var commandErrors = expvar.NewMap("command_errors")
+
+func errorText(err *cmderr.Error, suggestions []string, p i18n.Printer) string {
+	switch err.Kind {
+	case cmderr.KindUnknownCommand:
+		if len(suggestions) > 0 {
+			return p.T("error.unknown_command.suggest", err.Detail, "/"+strings.Join(suggestions, p.T("error.or")+"/"))
+		}
+		return p.T("error.unknown_command", err.Detail, commandFormat)
+	case cmderr.KindBadArguments:
+		if err.Message.Key != "" {
+			return p.T("error.bad_arguments.problem", err.Message, err.Detail)
+		}
+		return p.T("error.bad_arguments", err.Detail)
+	case cmderr.KindNotFound:
+		return p.T("error.not_found", err.Message)
+	case cmderr.KindForbidden:
+		return p.T("error.forbidden", err.Message)
+	case cmderr.KindConflict:
+		return p.T("error.conflict", err.Message)
+	case cmderr.KindUnavailable:
+		return p.T("error.unavailable")
+	case cmderr.KindRateLimited:
+		return p.Message(err.Message)
+	default:
+		return p.T("error.internal")
+	}
+}
+
+// renderError replies to the chat the update came from with a description of err.
+// Throttled button presses are not answered in the chat, the description is returned for the callback answer.
+func (c *Router) renderError(update tgbotapi.Update, err error) string {
+	cmdErr := cmderr.As(err)
+	commandErrors.Add(cmdErr.Kind.String(), 1)
+
+	log.Printf("Router: update %d failed - %v", update.UpdateID, err)
+
+	var (
+		chatID int64
+		user   *tgbotapi.User
+	)
+	switch {
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		chatID, user = update.CallbackQuery.Message.Chat.ID, update.CallbackQuery.From
+	case update.Message != nil:
+		chatID, user = update.Message.Chat.ID, update.Message.From
+	default:
+		return ""
+	}
+
+	var suggestions []string
+	if cmdErr.Kind == cmderr.KindUnknownCommand {
+		suggestions = c.suggest(cmdErr.Detail)
+	}
+
+	text := errorText(cmdErr, suggestions, c.localizer.For(user))
+	if update.CallbackQuery != nil && cmdErr.Kind == cmderr.KindRateLimited {
+		return text
+	}
+
+	_, sendErr := c.bot.Send(tgbotapi.NewMessage(chatID, text))
+	if sendErr != nil {
+		log.Printf("Router.renderError: error sending reply message to chat - %v", sendErr)
+	}
+
+	return ""
+}
+
+// suggest looks for declared commands and aliases similar to the unknown command.
+func (c *Router) suggest(unknown string) []string {
+	name := strings.TrimPrefix(unknown, "/")
+	if space := strings.IndexByte(name, ' '); space >= 0 {
+		name = name[:space]
+	}
+
+	known := c.commands.Names()
+	for alias := range c.aliases {
+		known = append(known, alias)
+	}
+
+	return path.Suggest(name, known)
+}
@@ -0,0 +1,53 @@
+package router
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/middleware"
+)
+
+// normalizeCommand rewrites "/alias@bot_name args" to "/full__command__name args"
+// and drops commands addressed to other bots.
+func (c *Router) normalizeCommand(next middleware.Handler) middleware.Handler {
+	return middleware.HandlerFunc(func(update tgbotapi.Update) error {
+		msg := update.Message
+		if msg == nil || !msg.IsCommand() {
+			return next.HandleUpdate(update)
+		}
+
+		withAt := msg.CommandWithAt()
+		name := withAt
+		if at := strings.IndexByte(withAt, '@'); at >= 0 {
+			name = withAt[:at]
+			if c.botUserName != "" && !strings.EqualFold(withAt[at+1:], c.botUserName) {
+				return nil
+			}
+		}
+
+		resolved := c.aliases.Resolve(name)
+		if resolved == withAt {
+			return next.HandleUpdate(update)
+		}
+
+		update.Message = rewriteCommand(msg, resolved)
+
+		return next.HandleUpdate(update)
+	})
+}
+
+func rewriteCommand(msg *tgbotapi.Message, command string) *tgbotapi.Message {
+	entities := append([]tgbotapi.MessageEntity(nil), *msg.Entities...)
+	oldLength := entities[0].Length
+
+	rewritten := *msg
+	rewritten.Text = "/" + command + msg.Text[oldLength:]
+	delta := len(command) + 1 - oldLength
+	entities[0].Length = len(command) + 1
+	for i := 1; i < len(entities); i++ {
+		entities[i].Offset += delta
+	}
+	rewritten.Entities = &entities
+
+	return &rewritten
+}
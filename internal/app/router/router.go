@@ -3,21 +3,56 @@ package router
 import (
 	"github.com/ozonmp/omp-bot/internal/app/commands/insurance"
 	"log"
-	"runtime/debug"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/cmderr"
 	"github.com/ozonmp/omp-bot/internal/app/commands/demo"
+	"github.com/ozonmp/omp-bot/internal/app/commands/language"
+	"github.com/ozonmp/omp-bot/internal/app/commands/menu"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	settingsCommands "github.com/ozonmp/omp-bot/internal/app/commands/settings"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/middleware"
 	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+	userSettings "github.com/ozonmp/omp-bot/internal/app/settings"
 )
 
 type Commander interface {
-	HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath)
-	HandleCommand(callback *tgbotapi.Message, commandPath path.CommandPath)
+	HandleCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error
+	HandleCommand(callback *tgbotapi.Message, commandPath path.CommandPath) error
 }
 
 type Router struct {
 	// bot
-	bot *tgbotapi.BotAPI
+	bot sender.Sender
+
+	// handler is the dispatching logic wrapped with middlewares
+	handler middleware.Handler
+	// guarded is the dispatching logic behind the access check, commands synthesized from an update
+	// are handed to it, so the other middlewares do not see one update twice
+	guarded           middleware.Handler
+	middlewares       []middleware.Middleware
+	domainMiddlewares map[string][]middleware.Middleware
+
+	// commands declared by domain commanders
+	commands *meta.Registry
+	// aliases of commands and the bot name accepted in /command@bot_name
+	aliases     path.Aliases
+	botUserName string
+
+	// localizer translates replies to the language of the user
+	localizer *i18n.Localizer
+	// answerer stops the loading animation of pressed buttons
+	answerer CallbackAnswerer
+
+	// menuCommander handles /start and navigation without typing commands
+	menuCommander *menu.MenuCommander
+	// languageCommander handles /language
+	languageCommander *language.LanguageCommander
+	// settingsCommander handles /settings
+	settingsCommander *settingsCommands.SettingsCommander
 
 	// demoCommander
 	demoCommander Commander
@@ -49,12 +84,50 @@ type Router struct {
 	// education
 }
 
+// Config is the part of the router which comes from the configuration of the bot.
+// cmd/bot, cmd/replay and the end-to-end tests build the router with it, so they handle updates alike.
+type Config struct {
+	// Roles are checked before commands and callbacks are handled, nil lets everybody run everything.
+	Roles auth.Resolver
+	// Aliases are short command names.
+	Aliases path.Aliases
+	// BotUserName makes the router ignore commands addressed to other bots in group chats.
+	BotUserName string
+	// Middlewares are applied to every update before the access check.
+	Middlewares []middleware.Middleware
+	// Answerer answers callback queries once they are handled, nil leaves them unanswered, e.g. in replays.
+	Answerer CallbackAnswerer
+}
+
+// CallbackAnswerer is implemented by *tgbotapi.BotAPI.
+type CallbackAnswerer interface {
+	AnswerCallbackQuery(config tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error)
+}
+
 func NewRouter(
-	bot *tgbotapi.BotAPI,
+	bot sender.Sender,
+	services insurance.Services,
+	localizer *i18n.Localizer,
+	settings *userSettings.Store,
+	config Config,
 ) *Router {
-	return &Router{
+	router := &Router{
 		// bot
 		bot: bot,
+		// localizer
+		localizer: localizer,
+		answerer:  config.Answerer,
+		// domainMiddlewares
+		domainMiddlewares: make(map[string][]middleware.Middleware),
+		// commands
+		commands: meta.NewRegistry(),
+		// aliases
+		aliases:     config.Aliases,
+		botUserName: config.BotUserName,
+		// languageCommander
+		languageCommander: language.NewLanguageCommander(bot, localizer),
+		// settingsCommander
+		settingsCommander: settingsCommands.NewSettingsCommander(bot, settings, localizer),
 		// demoCommander
 		demoCommander: demo.NewDemoCommander(bot),
 		// user
@@ -68,7 +141,7 @@ func NewRouter(
 		// subscription
 		// license
 		// insurance
-		insuranceCommander: insurance.NewInsuranceCommander(bot),
+		insuranceCommander: insurance.NewInsuranceCommander(bot, services, localizer, settings),
 		// payment
 		// storage
 		// streaming
@@ -84,33 +157,142 @@ func NewRouter(
 		// product
 		// education
 	}
+
+	router.menuCommander = menu.NewMenuCommander(bot, router.commands, config.Roles, localizer, router.run)
+
+	router.guarded = middleware.HandlerFunc(router.dispatch)
+	if config.Roles != nil {
+		router.guarded = auth.Middleware(config.Roles, router.commands.RequiredRole)(router.guarded)
+	}
+	router.Use(config.Middlewares...)
+	router.useCommanderMiddlewares("demo", router.demoCommander)
+	router.useCommanderMiddlewares("insurance", router.insuranceCommander)
+	router.commands.Add(router.menuCommander.Commands()...)
+	router.commands.Add(router.languageCommander.Commands()...)
+	router.commands.Add(router.settingsCommander.Commands()...)
+	router.addCommanderCommands(router.demoCommander)
+	router.addCommanderCommands(router.insuranceCommander)
+
+	return router
+}
+
+// commandProvider is implemented by domain commanders which declare their commands.
+type commandProvider interface {
+	Commands() []meta.Command
+}
+
+func (c *Router) addCommanderCommands(commander Commander) {
+	if provider, ok := commander.(commandProvider); ok {
+		c.commands.Add(provider.Commands()...)
+	}
+}
+
+// Commands returns the commands declared by all domains.
+func (c *Router) Commands() *meta.Registry {
+	return c.commands
+}
+
+// domainMiddlewareProvider is implemented by domain commanders which need their own middlewares.
+type domainMiddlewareProvider interface {
+	Middlewares() []middleware.Middleware
+}
+
+func (c *Router) useCommanderMiddlewares(domain string, commander Commander) {
+	if provider, ok := commander.(domainMiddlewareProvider); ok {
+		c.UseDomain(domain, provider.Middlewares()...)
+	}
+}
+
+// Use appends middlewares applied to every update. Recovery from panics is always the outermost one,
+// it is followed by resolving of command aliases, so other middlewares see full command names.
+// The access check is always the innermost one.
+func (c *Router) Use(middlewares ...middleware.Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+	c.handler = middleware.Chain(
+		c.guarded,
+		append([]middleware.Middleware{middleware.Recover(), c.normalizeCommand}, c.middlewares...)...,
+	)
+}
+
+// UseDomain appends middlewares applied only to commands and callbacks of the domain.
+func (c *Router) UseDomain(domain string, middlewares ...middleware.Middleware) {
+	c.domainMiddlewares[domain] = append(c.domainMiddlewares[domain], middlewares...)
 }
 
 func (c *Router) HandleUpdate(update tgbotapi.Update) {
-	defer func() {
-		if panicValue := recover(); panicValue != nil {
-			log.Printf("recovered from panic: %v\n%v", panicValue, string(debug.Stack()))
-		}
-	}()
+	var answer string
+	if err := c.handler.HandleUpdate(update); err != nil {
+		answer = c.renderError(update, err)
+	}
+	if update.CallbackQuery != nil {
+		c.answerCallback(update.CallbackQuery, answer)
+	}
+}
+
+// run handles a command synthesized from the update being handled, e.g. by the menu.
+// It skips the middlewares which have already seen the original update, the access is checked again.
+func (c *Router) run(update tgbotapi.Update) {
+	if err := c.guarded.HandleUpdate(update); err != nil {
+		c.renderError(update, err)
+	}
+}
+
+// answerCallback is called for every callback query, including the ones dropped by middlewares,
+// otherwise the button keeps loading until Telegram gives up.
+func (c *Router) answerCallback(callback *tgbotapi.CallbackQuery, text string) {
+	if c.answerer == nil {
+		return
+	}
 
+	if _, err := c.answerer.AnswerCallbackQuery(tgbotapi.NewCallback(callback.ID, text)); err != nil {
+		log.Printf("Router.answerCallback: error answering callback query - %v", err)
+	}
+}
+
+func (c *Router) dispatch(update tgbotapi.Update) error {
 	switch {
 	case update.CallbackQuery != nil:
-		c.handleCallback(update.CallbackQuery)
+		return c.handleCallback(update)
 	case update.Message != nil:
-		c.handleMessage(update.Message)
+		return c.handleMessage(update)
 	}
+
+	return nil
+}
+
+// withDomain runs route under the middlewares registered for the domain.
+func (c *Router) withDomain(update tgbotapi.Update, domain string, route func() error) error {
+	handler := middleware.Chain(
+		middleware.HandlerFunc(func(tgbotapi.Update) error { return route() }),
+		c.domainMiddlewares[domain]...,
+	)
+
+	return handler.HandleUpdate(update)
 }
 
-func (c *Router) handleCallback(callback *tgbotapi.CallbackQuery) {
+func (c *Router) handleCallback(update tgbotapi.Update) error {
+	callback := update.CallbackQuery
 	callbackPath, err := path.ParseCallback(callback.Data)
 	if err != nil {
 		log.Printf("Router.handleCallback: error parsing callback data `%s` - %v", callback.Data, err)
-		return
+		return cmderr.UnknownCommand(callback.Data)
 	}
 
+	return c.withDomain(update, callbackPath.Domain, func() error {
+		return c.routeCallback(callback, callbackPath)
+	})
+}
+
+func (c *Router) routeCallback(callback *tgbotapi.CallbackQuery, callbackPath path.CallbackPath) error {
 	switch callbackPath.Domain {
+	case "menu":
+		return c.menuCommander.HandleCallback(callback, callbackPath)
+	case "language":
+		return c.languageCommander.HandleCallback(callback, callbackPath)
+	case "settings":
+		return c.settingsCommander.HandleCallback(callback, callbackPath)
 	case "demo":
-		c.demoCommander.HandleCallback(callback, callbackPath)
+		return c.demoCommander.HandleCallback(callback, callbackPath)
 	case "user":
 		break
 	case "access":
@@ -132,7 +314,7 @@ func (c *Router) handleCallback(callback *tgbotapi.CallbackQuery) {
 	case "license":
 		break
 	case "insurance":
-		c.insuranceCommander.HandleCallback(callback, callbackPath)
+		return c.insuranceCommander.HandleCallback(callback, callbackPath)
 	case "payment":
 		break
 	case "storage":
@@ -162,26 +344,41 @@ func (c *Router) handleCallback(callback *tgbotapi.CallbackQuery) {
 	case "education":
 		break
 	default:
-		log.Printf("Router.handleCallback: unknown domain - %s", callbackPath.Domain)
+		log.Printf("Router.routeCallback: unknown domain - %s", callbackPath.Domain)
 	}
+
+	return cmderr.UnknownCommand(callbackPath.String())
 }
 
-func (c *Router) handleMessage(msg *tgbotapi.Message) {
+func (c *Router) handleMessage(update tgbotapi.Update) error {
+	msg := update.Message
 	if !msg.IsCommand() {
-		c.showCommandFormat(msg)
-
-		return
+		return c.menuCommander.Show(msg)
+	}
+	switch msg.Command() {
+	case "start":
+		return c.menuCommander.Start(msg)
+	case "language":
+		return c.languageCommander.HandleCommand(msg, path.CommandPath{CommandName: "language"})
+	case "settings":
+		return c.settingsCommander.HandleCommand(msg, path.CommandPath{CommandName: "settings"})
 	}
 
 	commandPath, err := path.ParseCommand(msg.Command())
 	if err != nil {
-		log.Printf("Router.handleCallback: error parsing callback data `%s` - %v", msg.Command(), err)
-		return
+		log.Printf("Router.handleMessage: error parsing command `%s` - %v", msg.Command(), err)
+		return cmderr.UnknownCommand("/" + msg.Command())
 	}
 
+	return c.withDomain(update, commandPath.Domain, func() error {
+		return c.routeCommand(msg, commandPath)
+	})
+}
+
+func (c *Router) routeCommand(msg *tgbotapi.Message, commandPath path.CommandPath) error {
 	switch commandPath.Domain {
 	case "demo":
-		c.demoCommander.HandleCommand(msg, commandPath)
+		return c.demoCommander.HandleCommand(msg, commandPath)
 	case "user":
 		break
 	case "access":
@@ -203,7 +400,7 @@ func (c *Router) handleMessage(msg *tgbotapi.Message) {
 	case "license":
 		break
 	case "insurance":
-		c.insuranceCommander.HandleCommand(msg, commandPath)
+		return c.insuranceCommander.HandleCommand(msg, commandPath)
 	case "payment":
 		break
 	case "storage":
@@ -233,15 +430,8 @@ func (c *Router) handleMessage(msg *tgbotapi.Message) {
 	case "education":
 		break
 	default:
-		log.Printf("Router.handleCallback: unknown domain - %s", commandPath.Domain)
+		log.Printf("Router.routeCommand: unknown domain - %s", commandPath.Domain)
 	}
-}
 
-func (c *Router) showCommandFormat(inputMessage *tgbotapi.Message) {
-	outputMsg := tgbotapi.NewMessage(inputMessage.Chat.ID, "Command format: /{command}__{domain}__{subdomain}")
-
-	_, err := c.bot.Send(outputMsg)
-	if err != nil {
-		log.Printf("Router.showCommandFormat: error sending reply message to chat - %v", err)
-	}
+	return cmderr.UnknownCommand(commandPath.String())
 }
@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"expvar"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+// jobMetrics counts runs and failures by job name.
+var jobMetrics = expvar.NewMap("scheduler_jobs")
+
+const bucket = "scheduler.jobs"
+
+// Job is run periodically, now is the time of the run.
+type Job interface {
+	Run(now time.Time) error
+}
+
+type JobFunc func(now time.Time) error
+
+func (f JobFunc) Run(now time.Time) error {
+	return f(now)
+}
+
+type entry struct {
+	name  string
+	every time.Duration
+	job   Job
+}
+
+// state is what is kept about a job between restarts.
+type state struct {
+	LastRun time.Time `json:"last_run"`
+}
+
+// Scheduler runs jobs in the background, each job in its own goroutine, so a slow job does not delay the others.
+// The time of the last run is persisted, after a restart a job waits for the rest of its interval.
+type Scheduler struct {
+	store storage.Store
+	now   func() time.Time
+	jobs  []entry
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+func New(store storage.Store) *Scheduler {
+	return &Scheduler{
+		store: store,
+		now:   time.Now,
+		stop:  make(chan struct{}),
+	}
+}
+
+// Add registers the job to run every interval, jobs must be added before Start.
+func (s *Scheduler) Add(name string, every time.Duration, job Job) {
+	s.jobs = append(s.jobs, entry{name: name, every: every, job: job})
+}
+
+func (s *Scheduler) Start() {
+	s.wg.Add(len(s.jobs))
+	for _, e := range s.jobs {
+		go s.loop(e)
+	}
+}
+
+// Stop waits for the running jobs to finish, jobs are not interrupted.
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(e entry) {
+	defer s.wg.Done()
+
+	last, err := s.lastRun(e.name)
+	if err != nil {
+		log.Printf("Scheduler: cannot load the state of job %s - %v", e.name, err)
+	}
+
+	for {
+		wait := last.Add(e.every).Sub(s.now())
+		if wait < 0 {
+			wait = 0
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		last = s.now()
+		s.run(e, last)
+	}
+}
+
+func (s *Scheduler) run(e entry, now time.Time) {
+	jobMetrics.Add(e.name+".runs", 1)
+	if err := e.job.Run(now); err != nil {
+		jobMetrics.Add(e.name+".failed", 1)
+		log.Printf("Scheduler: job %s failed - %v", e.name, err)
+	}
+
+	err := s.store.Update(func(tx storage.Tx) error {
+		return tx.Put(bucket, e.name, state{LastRun: now})
+	})
+	if err != nil {
+		log.Printf("Scheduler: cannot save the state of job %s - %v", e.name, err)
+	}
+}
+
+func (s *Scheduler) lastRun(name string) (time.Time, error) {
+	var st state
+	err := s.store.View(func(tx storage.Tx) error {
+		_, err := tx.Get(bucket, name, &st)
+
+		return err
+	})
+
+	return st.LastRun, err
+}
@@ -0,0 +1,332 @@
+package sender
+
+import (
+	"errors"
+	"expvar"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+var (
+	ErrDispatcherClosed = errors.New("dispatcher is closed")
+	// ErrQueueFull is returned instead of waiting, so a chat flooding its queue does not hold back the callers.
+	ErrQueueFull = errors.New("dispatcher queue is full")
+
+	deliveryMetrics = expvar.NewMap("deliveries")
+)
+
+type Status int
+
+const (
+	StatusQueued Status = iota
+	StatusSending
+	StatusSent
+	StatusFailed
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusQueued:
+		return "queued"
+	case StatusSending:
+		return "sending"
+	case StatusSent:
+		return "sent"
+	default:
+		return "failed"
+	}
+}
+
+// Delivery tracks a message handed to the Dispatcher, long messages are delivered as several parts.
+type Delivery struct {
+	ID     uint64
+	ChatID int64
+
+	parts []tgbotapi.Chattable
+	done  chan struct{}
+
+	mu       sync.Mutex
+	status   Status
+	attempts int
+	msg      tgbotapi.Message
+	err      error
+}
+
+func (d *Delivery) Status() Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.status
+}
+
+// Attempts returns the number of send attempts made so far.
+func (d *Delivery) Attempts() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.attempts
+}
+
+// Wait blocks until the delivery is finished and returns the last sent part.
+func (d *Delivery) Wait() (tgbotapi.Message, error) {
+	<-d.done
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.msg, d.err
+}
+
+func (d *Delivery) setStatus(status Status) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.status = status
+}
+
+func (d *Delivery) finish(msg tgbotapi.Message, err error) {
+	d.mu.Lock()
+	d.msg, d.err = msg, err
+	if err != nil {
+		d.status = StatusFailed
+	} else {
+		d.status = StatusSent
+	}
+	d.mu.Unlock()
+
+	close(d.done)
+}
+
+type Backoff struct {
+	Initial     time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+var DefaultBackoff = Backoff{
+	Initial:     500 * time.Millisecond,
+	Max:         30 * time.Second,
+	MaxAttempts: 5,
+}
+
+// delay returns the exponential delay with jitter before the given retry.
+func (b Backoff) delay(retry int) time.Duration {
+	d := b.Initial << uint(retry-1)
+	if d > b.Max || d <= 0 {
+		d = b.Max
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// recentDeliveries is the number of finished deliveries kept for status lookups.
+const recentDeliveries = 1000
+
+// Dispatcher queues outgoing messages and retries transient failures, it is the only layer repeating
+// answers 429 after retry_after. Messages of one chat are always sent by the same worker, so their order is kept.
+type Dispatcher struct {
+	next    Sender
+	backoff Backoff
+	queues  []chan *Delivery
+	sleep   func(time.Duration)
+
+	// queuesMu guards queues from being closed while a message is queued
+	queuesMu sync.RWMutex
+	closed   bool
+	wg       sync.WaitGroup
+
+	mu     sync.Mutex
+	lastID uint64
+	byID   map[uint64]*Delivery
+	recent []uint64
+}
+
+func NewDispatcher(next Sender, workers, queueSize int, backoff Backoff) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+
+	d := &Dispatcher{
+		next:    next,
+		backoff: backoff,
+		queues:  make([]chan *Delivery, workers),
+		sleep:   time.Sleep,
+		byID:    make(map[uint64]*Delivery),
+	}
+
+	d.wg.Add(workers)
+	for i := range d.queues {
+		d.queues[i] = make(chan *Delivery, queueSize)
+		go d.work(d.queues[i])
+	}
+
+	return d
+}
+
+// Send queues the message and waits for its delivery.
+func (d *Dispatcher) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	delivery, err := d.Enqueue(c)
+	if err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	return delivery.Wait()
+}
+
+// Enqueue queues the message without waiting, its progress is reported by the returned delivery.
+// It fails with ErrQueueFull when the queue of the chat's worker is full.
+func (d *Dispatcher) Enqueue(c tgbotapi.Chattable) (*Delivery, error) {
+	d.queuesMu.RLock()
+	defer d.queuesMu.RUnlock()
+
+	if d.closed {
+		return nil, ErrDispatcherClosed
+	}
+
+	delivery := &Delivery{
+		ChatID: ChatID(c),
+		parts:  Split(c),
+		done:   make(chan struct{}),
+	}
+	d.remember(delivery)
+
+	shard := uint64(delivery.ChatID) % uint64(len(d.queues))
+	select {
+	case d.queues[shard] <- delivery:
+	default:
+		deliveryMetrics.Add("rejected", 1)
+		delivery.finish(tgbotapi.Message{}, ErrQueueFull)
+
+		return nil, ErrQueueFull
+	}
+	deliveryMetrics.Add("queued", 1)
+
+	return delivery, nil
+}
+
+func (d *Dispatcher) remember(delivery *Delivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.lastID++
+	delivery.ID = d.lastID
+	d.byID[delivery.ID] = delivery
+	d.recent = append(d.recent, delivery.ID)
+	if len(d.recent) > recentDeliveries {
+		delete(d.byID, d.recent[0])
+		d.recent = d.recent[1:]
+	}
+}
+
+// Delivery returns one of the recent deliveries by its ID.
+func (d *Dispatcher) Delivery(id uint64) (*Delivery, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, found := d.byID[id]
+
+	return delivery, found
+}
+
+// Saturation returns the fill ratio of the fullest worker queue.
+func (d *Dispatcher) Saturation() float64 {
+	var max float64
+	for _, queue := range d.queues {
+		if cap(queue) == 0 {
+			continue
+		}
+		if ratio := float64(len(queue)) / float64(cap(queue)); ratio > max {
+			max = ratio
+		}
+	}
+
+	return max
+}
+
+// Close stops accepting messages and waits for the queued ones.
+func (d *Dispatcher) Close() {
+	d.queuesMu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, queue := range d.queues {
+			close(queue)
+		}
+	}
+	d.queuesMu.Unlock()
+
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) work(queue chan *Delivery) {
+	defer d.wg.Done()
+
+	for delivery := range queue {
+		delivery.setStatus(StatusSending)
+
+		var (
+			msg tgbotapi.Message
+			err error
+		)
+		for _, part := range delivery.parts {
+			msg, err = d.deliver(delivery, part)
+			if err != nil {
+				break
+			}
+		}
+
+		if err != nil {
+			deliveryMetrics.Add("failed", 1)
+			log.Printf("Dispatcher: delivery %d to chat %d failed after %d attempts - %v",
+				delivery.ID, delivery.ChatID, delivery.Attempts(), err)
+		} else {
+			deliveryMetrics.Add("sent", 1)
+		}
+		delivery.finish(msg, err)
+	}
+}
+
+func (d *Dispatcher) deliver(delivery *Delivery, part tgbotapi.Chattable) (tgbotapi.Message, error) {
+	for retry := 0; ; retry++ {
+		delivery.mu.Lock()
+		delivery.attempts++
+		delivery.mu.Unlock()
+
+		msg, err := d.next.Send(part)
+		if err == nil || !IsTransient(err) || retry+1 >= d.backoff.MaxAttempts {
+			return msg, err
+		}
+
+		wait := RetryAfter(err)
+		if wait == 0 {
+			wait = d.backoff.delay(retry + 1)
+		}
+		deliveryMetrics.Add("retried", 1)
+		log.Printf("Dispatcher: retrying delivery %d in %v - %v", delivery.ID, wait, err)
+		d.sleep(wait)
+	}
+}
+
+// IsTransient reports whether sending may succeed if repeated.
+func IsTransient(err error) bool {
+	var apiErr tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.RetryAfter > 0 {
+			return true
+		}
+		for _, prefix := range []string{"Too Many Requests", "Internal Server Error", "Bad Gateway", "Gateway Timeout"} {
+			if strings.HasPrefix(apiErr.Message, prefix) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// Network failures of the HTTP client and broken responses.
+	return true
+}
@@ -0,0 +1,129 @@
+package sender
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// scriptedSender answers the sends with the given errors in turn and succeeds afterwards.
+type scriptedSender struct {
+	mu    sync.Mutex
+	errs  []error
+	sends int
+}
+
+func (s *scriptedSender) Send(tgbotapi.Chattable) (tgbotapi.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sends++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return tgbotapi.Message{}, err
+	}
+
+	return tgbotapi.Message{MessageID: s.sends}, nil
+}
+
+func newTestDispatcher(next Sender, backoff Backoff) (*Dispatcher, *[]time.Duration) {
+	var slept []time.Duration
+	d := NewDispatcher(next, 1, 10, backoff)
+	d.sleep = func(wait time.Duration) {
+		slept = append(slept, wait)
+	}
+
+	return d, &slept
+}
+
+func TestDispatcherWaitsRetryAfter(t *testing.T) {
+	next := &scriptedSender{errs: []error{
+		tgbotapi.Error{Message: "Too Many Requests: retry after 3", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 3}},
+	}}
+	d, slept := newTestDispatcher(next, Backoff{Initial: time.Millisecond, Max: time.Millisecond, MaxAttempts: 3})
+
+	msg, err := d.Send(tgbotapi.NewMessage(1, "hi"))
+	d.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.MessageID != 2 {
+		t.Errorf("got message %d, want the second send", msg.MessageID)
+	}
+	if len(*slept) != 1 || (*slept)[0] != 3*time.Second {
+		t.Errorf("slept %v, want [3s]", *slept)
+	}
+}
+
+func TestDispatcherBacksOffWithoutRetryAfter(t *testing.T) {
+	next := &scriptedSender{errs: []error{
+		tgbotapi.Error{Message: "Bad Gateway"},
+		errors.New("connection reset"),
+	}}
+	d, slept := newTestDispatcher(next, Backoff{Initial: time.Second, Max: 2 * time.Second, MaxAttempts: 3})
+
+	delivery, err := d.Enqueue(tgbotapi.NewMessage(1, "hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := delivery.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+
+	if delivery.Attempts() != 3 || delivery.Status() != StatusSent {
+		t.Errorf("attempts %d, status %v", delivery.Attempts(), delivery.Status())
+	}
+	if len(*slept) != 2 || (*slept)[0] > time.Second || (*slept)[1] > 2*time.Second {
+		t.Errorf("slept %v, want the backoff delays", *slept)
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	tooMany := tgbotapi.Error{Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 1}}
+	next := &scriptedSender{errs: []error{tooMany, tooMany, tooMany}}
+	d, slept := newTestDispatcher(next, Backoff{Initial: time.Millisecond, Max: time.Millisecond, MaxAttempts: 2})
+
+	_, err := d.Send(tgbotapi.NewMessage(1, "hi"))
+	d.Close()
+	if !errors.As(err, &tgbotapi.Error{}) {
+		t.Errorf("got %v, want the API error", err)
+	}
+	if next.sends != 2 || len(*slept) != 1 {
+		t.Errorf("sent %d times and slept %v, want 2 sends and one wait", next.sends, *slept)
+	}
+}
+
+func TestDispatcherDoesNotRetryPermanentErrors(t *testing.T) {
+	next := &scriptedSender{errs: []error{tgbotapi.Error{Message: "Bad Request: can't parse entities"}}}
+	d, slept := newTestDispatcher(next, DefaultBackoff)
+
+	_, err := d.Send(tgbotapi.NewMessage(1, "<b>"))
+	d.Close()
+	if err == nil || next.sends != 1 || len(*slept) != 0 {
+		t.Errorf("got %v after %d sends and waits %v, want one failed send", err, next.sends, *slept)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{tgbotapi.Error{ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 5}}, true},
+		{tgbotapi.Error{Message: "Too Many Requests: retry after 5"}, true},
+		{tgbotapi.Error{Message: "Gateway Timeout"}, true},
+		{tgbotapi.Error{Message: "Forbidden: bot was blocked by the user"}, false},
+		{errors.New("connection refused"), true},
+	} {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+	if wait := RetryAfter(tgbotapi.Error{ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 5}}); wait != 5*time.Second {
+		t.Errorf("RetryAfter = %v, want 5s", wait)
+	}
+}
@@ -0,0 +1,38 @@
+package sender
+
+import (
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Sender delivers messages to Telegram, *tgbotapi.BotAPI is the simplest implementation.
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
+// ChatID returns the destination chat of the supported configs and 0 for the others.
+func ChatID(c tgbotapi.Chattable) int64 {
+	switch config := c.(type) {
+	case tgbotapi.MessageConfig:
+		return config.ChatID
+	case *tgbotapi.MessageConfig:
+		return config.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return config.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return config.ChatID
+	case tgbotapi.DocumentConfig:
+		return config.ChatID
+	case tgbotapi.PhotoConfig:
+		return config.ChatID
+	case tgbotapi.ChatActionConfig:
+		return config.ChatID
+	default:
+		return 0
+	}
+}
+
+func keyOf(chatID int64) string {
+	return strconv.FormatInt(chatID, 10)
+}
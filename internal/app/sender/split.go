@@ -0,0 +1,203 @@
+package sender
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// MaxMessageLength is the Telegram limit for the text of a single message in UTF-16 code units,
+// characters outside the Basic Multilingual Plane like most emoji count twice.
+const MaxMessageLength = 4096
+
+// maxEntityLength is the length of the longest HTML entity the renderer produces, like &#128512;.
+const maxEntityLength = 10
+
+// Split breaks text messages exceeding MaxMessageLength into several ones,
+// preferably on line breaks. The reply markup is kept on the last part only.
+func Split(c tgbotapi.Chattable) []tgbotapi.Chattable {
+	msg, ok := c.(tgbotapi.MessageConfig)
+	if !ok || Length(msg.Text) <= MaxMessageLength {
+		return []tgbotapi.Chattable{c}
+	}
+
+	split := SplitText
+	if msg.ParseMode == tgbotapi.ModeHTML {
+		split = SplitHTML
+	}
+	chunks := split(msg.Text, MaxMessageLength)
+	parts := make([]tgbotapi.Chattable, 0, len(chunks))
+	for i, chunk := range chunks {
+		part := msg
+		part.Text = chunk
+		if i != len(chunks)-1 {
+			part.ReplyMarkup = nil
+		}
+		if i != 0 {
+			part.ReplyToMessageID = 0
+		}
+		parts = append(parts, part)
+	}
+
+	return parts
+}
+
+// Length returns the length of the text as Telegram counts it, in UTF-16 code units.
+func Length(text string) int {
+	length := 0
+	for _, r := range text {
+		length += units(r)
+	}
+
+	return length
+}
+
+func units(r rune) int {
+	if r > 0xFFFF {
+		return 2
+	}
+
+	return 1
+}
+
+// SplitText cuts text into chunks of at most limit UTF-16 code units.
+func SplitText(text string, limit int) []string {
+	var chunks []string
+	for Length(text) > limit {
+		cut := byteOffset(text, limit)
+		if newline := strings.LastIndexByte(text[:cut], '\n'); newline > 0 {
+			chunks = append(chunks, text[:newline])
+			text = text[newline+1:]
+			continue
+		}
+		chunks = append(chunks, text[:cut])
+		text = text[cut:]
+	}
+
+	return append(chunks, text)
+}
+
+// byteOffset returns the byte length of the longest prefix fitting into the given number of UTF-16 code units.
+func byteOffset(text string, limit int) int {
+	offset, length := 0, 0
+	for offset < len(text) {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if length+units(r) > limit {
+			break
+		}
+		length += units(r)
+		offset += size
+	}
+
+	return offset
+}
+
+// SplitHTML cuts text in the HTML parse mode like SplitText, but never inside a tag or an entity,
+// as Telegram rejects such chunks. The tags open at a cut are closed at the end of the chunk
+// and opened again at the start of the next one.
+func SplitHTML(text string, limit int) []string {
+	var chunks []string
+	for Length(text) > limit {
+		chunk, rest := cutHTML(text, limit)
+		chunks = append(chunks, chunk)
+		text = rest
+	}
+
+	return append(chunks, text)
+}
+
+// cutHTML returns the first chunk of at most limit UTF-16 code units with its tags closed and the rest
+// of the text with these tags reopened.
+func cutHTML(text string, limit int) (string, string) {
+	for budget := limit; budget > 0; {
+		cut, next := htmlCut(text, budget)
+		open := openTags(text[:cut])
+		closing := closeTags(open)
+		if Length(text[:cut])+Length(closing) > limit {
+			budget--
+			if room := limit - Length(closing); room < budget {
+				budget = room
+			}
+			continue
+		}
+
+		reopening := strings.Join(open, "")
+		if next <= len(reopening) {
+			// The chunk would hold nothing but the reopened tags.
+			break
+		}
+
+		return text[:cut] + closing, reopening + text[next:]
+	}
+
+	// Only a tag longer than the limit gets here, a broken chunk is better than none.
+	cut := byteOffset(text, limit)
+	return text[:cut], text[cut:]
+}
+
+// htmlCut returns where the chunk of at most budget code units ends and where the rest starts.
+func htmlCut(text string, budget int) (int, int) {
+	cut := byteOffset(text, budget)
+	if newline := strings.LastIndexByte(text[:cut], '\n'); newline > 0 {
+		return newline, newline + 1
+	}
+	if lt := strings.LastIndexByte(text[:cut], '<'); lt >= 0 && strings.IndexByte(text[lt:cut], '>') < 0 {
+		cut = lt
+	}
+	if amp := strings.LastIndexByte(text[:cut], '&'); amp >= 0 && cut-amp < maxEntityLength &&
+		strings.IndexByte(text[amp:cut], ';') < 0 {
+		cut = amp
+	}
+
+	return cut, cut
+}
+
+// openTags returns the opening tags left unclosed in the text, outermost first.
+func openTags(text string) []string {
+	var open []string
+	for {
+		lt := strings.IndexByte(text, '<')
+		if lt < 0 {
+			return open
+		}
+		gt := strings.IndexByte(text[lt:], '>')
+		if gt < 0 {
+			return open
+		}
+		tag := text[lt : lt+gt+1]
+		text = text[lt+gt+1:]
+
+		switch {
+		case strings.HasPrefix(tag, "</"):
+			name := tagName(tag)
+			for i := len(open) - 1; i >= 0; i-- {
+				if tagName(open[i]) == name {
+					open = append(open[:i], open[i+1:]...)
+					break
+				}
+			}
+		case !strings.HasSuffix(tag, "/>"):
+			open = append(open, tag)
+		}
+	}
+}
+
+// closeTags returns the closing tags for the open ones in reverse order.
+func closeTags(open []string) string {
+	var closing strings.Builder
+	for i := len(open) - 1; i >= 0; i-- {
+		closing.WriteString("</" + tagName(open[i]) + ">")
+	}
+
+	return closing.String()
+}
+
+func tagName(tag string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(tag, "<"), "/")
+	if end := strings.IndexAny(name, " \t\n/>"); end >= 0 {
+		name = name[:end]
+	}
+
+	return name
+}
@@ -0,0 +1,132 @@
+package sender
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestLengthCountsUTF16Units(t *testing.T) {
+	for text, want := range map[string]int{
+		"":       0,
+		"abc":    3,
+		"машина": 6,
+		"🚗":      2,
+		"a🚗b":    4,
+		"&amp;🚗": 7,
+	} {
+		if got := Length(text); got != want {
+			t.Errorf("Length(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{"short", "abc", 5, []string{"abc"}},
+		{"on newline", "abc\ndef\ngh", 8, []string{"abc\ndef", "gh"}},
+		{"hard cut", "abcdefgh", 3, []string{"abc", "def", "gh"}},
+		{"emoji kept whole", "a🚗🚗", 4, []string{"a🚗", "🚗"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitText(tt.text, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("SplitText(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{"on newline", "<b>ab</b>\ncd", 10, []string{"<b>ab</b>", "cd"}},
+		{"not inside entity", "ab&amp;cd", 5, []string{"ab", "&amp;", "cd"}},
+		{"not inside tag", "abc<i>d</i>", 8, []string{"abc", "<i>d</i>"}},
+		{"tag closed and reopened", "<b>abcdefgh</b>", 12, []string{"<b>abcde</b>", "<b>fgh</b>"}},
+		{"nested tags", "<b><i>abcdefgh</i></b>", 20, []string{"<b><i>abcdef</i></b>", "<b><i>gh</i></b>"}},
+		{
+			"attributes reopened",
+			`<a href="https://t.me">abcdefgh</a>`, 31,
+			[]string{`<a href="https://t.me">abcd</a>`, `<a href="https://t.me">efgh</a>`},
+		},
+		{"newline inside tag", "<b>ab\ncd</b>", 9, []string{"<b>ab</b>", "<b>cd</b>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitHTML(tt.text, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("SplitHTML(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitHTMLKeepsChunksValid(t *testing.T) {
+	// A long claim description rendered with entities inside a bold block.
+	text := "<b>Claim</b>\n<i>" + strings.Repeat("Tom &amp; Jerry &lt;3 🚗 ", 400) + "</i>"
+
+	chunks := SplitHTML(text, MaxMessageLength)
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want several", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if length := Length(chunk); length > MaxMessageLength {
+			t.Errorf("chunk %d is %d units long", i, length)
+		}
+		if open := openTags(chunk); len(open) != 0 {
+			t.Errorf("chunk %d leaves %q open", i, open)
+		}
+		if lt := strings.LastIndexByte(chunk, '<'); strings.LastIndexByte(chunk, '>') < lt {
+			t.Errorf("chunk %d ends inside a tag: %q", i, chunk[lt:])
+		}
+		if amp := strings.LastIndexByte(chunk, '&'); strings.LastIndexByte(chunk, ';') < amp {
+			t.Errorf("chunk %d ends inside an entity: %q", i, chunk[amp:])
+		}
+		if i > 0 && !strings.HasPrefix(chunk, "<i>") {
+			t.Errorf("chunk %d does not reopen the italic: %q", i, chunk[:10])
+		}
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Next", "next"),
+	))
+	msg := tgbotapi.NewMessage(1, "<b>"+strings.Repeat("a", MaxMessageLength)+"</b>")
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyToMessageID = 7
+	msg.ReplyMarkup = markup
+
+	parts := Split(msg)
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(parts))
+	}
+	first, last := parts[0].(tgbotapi.MessageConfig), parts[1].(tgbotapi.MessageConfig)
+	if first.ReplyMarkup != nil || first.ReplyToMessageID != 7 {
+		t.Errorf("first part markup %v, reply to %d", first.ReplyMarkup, first.ReplyToMessageID)
+	}
+	if last.ReplyMarkup == nil || last.ReplyToMessageID != 0 {
+		t.Errorf("last part markup %v, reply to %d", last.ReplyMarkup, last.ReplyToMessageID)
+	}
+	if !strings.HasSuffix(first.Text, "</b>") || !strings.HasPrefix(last.Text, "<b>") {
+		t.Errorf("bold is not carried over: %q ... %q", first.Text[len(first.Text)-10:], last.Text)
+	}
+
+	if parts := Split(tgbotapi.NewMessage(1, "short")); len(parts) != 1 {
+		t.Errorf("short message split into %d parts", len(parts))
+	}
+}
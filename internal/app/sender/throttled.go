@@ -0,0 +1,70 @@
+package sender
+
+import (
+	"errors"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/ratelimit"
+)
+
+// Limits published by Telegram for bots.
+var (
+	GlobalRule      = ratelimit.Rule{Rate: 30, Burst: 30}
+	PrivateChatRule = ratelimit.Rule{Rate: 1, Burst: 3}
+	GroupChatRule   = ratelimit.Rule{Rate: 20.0 / 60, Burst: 20}
+)
+
+// Throttled delays messages to stay within Telegram limits. Answers 429 are returned as they are,
+// the Dispatcher repeats them after retry_after.
+type Throttled struct {
+	next    Sender
+	global  *ratelimit.Limiter
+	private *ratelimit.Limiter
+	group   *ratelimit.Limiter
+	sleep   func(time.Duration)
+}
+
+func NewThrottled(next Sender) *Throttled {
+	return &Throttled{
+		next:    next,
+		global:  ratelimit.NewLimiter(GlobalRule),
+		private: ratelimit.NewLimiter(PrivateChatRule),
+		group:   ratelimit.NewLimiter(GroupChatRule),
+		sleep:   time.Sleep,
+	}
+}
+
+func (t *Throttled) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	t.sleep(t.reserve(ChatID(c)))
+
+	return t.next.Send(c)
+}
+
+func (t *Throttled) reserve(chatID int64) time.Duration {
+	wait := t.global.Reserve("")
+
+	var chatWait time.Duration
+	switch {
+	case chatID > 0:
+		chatWait = t.private.Reserve(keyOf(chatID))
+	case chatID < 0:
+		chatWait = t.group.Reserve(keyOf(chatID))
+	}
+
+	if chatWait > wait {
+		return chatWait
+	}
+
+	return wait
+}
+
+// RetryAfter returns the delay requested by Telegram in the error or 0.
+func RetryAfter(err error) time.Duration {
+	var apiErr tgbotapi.Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return time.Duration(apiErr.RetryAfter) * time.Second
+	}
+
+	return 0
+}
@@ -0,0 +1,160 @@
+package settings
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+// Notification topics users may opt in to.
+const (
+	NotifyExpiry  = "expiry"
+	NotifyChanges = "changes"
+)
+
+// DefaultPageSize is the page size of lists for users who have not chosen one.
+const DefaultPageSize = 3
+
+// Topics lists all notification topics.
+var Topics = []string{NotifyExpiry, NotifyChanges}
+
+// Settings are preferences of a user, zero values mean defaults of the bot.
+// Sort orders the car list only, policies and claims are listed by ID as their services have no order.
+type Settings struct {
+	PageSize      uint64          `json:"page_size,omitempty"`
+	Language      string          `json:"language,omitempty"`
+	Sort          string          `json:"sort,omitempty"`
+	Timezone      string          `json:"timezone,omitempty"`
+	Notifications map[string]bool `json:"notifications,omitempty"`
+}
+
+// PageSizeOr returns the page size or the default one if the user has not chosen it.
+func (s Settings) PageSizeOr(def uint64) uint64 {
+	if s.PageSize == 0 {
+		return def
+	}
+
+	return s.PageSize
+}
+
+// SortOr returns the sort order or the default one if the user has not chosen it.
+func (s Settings) SortOr(def string) string {
+	if s.Sort == "" {
+		return def
+	}
+
+	return s.Sort
+}
+
+// Location returns the time zone of the user, UTC by default.
+func (s Settings) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// defaultTopics are sent until the user turns them off, the others until the user turns them on.
+// Change notifications come only for the watched entities, so they are on by default.
+var defaultTopics = map[string]bool{NotifyChanges: true}
+
+func (s Settings) Notify(topic string) bool {
+	if on, set := s.Notifications[topic]; set {
+		return on
+	}
+
+	return defaultTopics[topic]
+}
+
+const bucket = "user.settings"
+
+// Store keeps settings of users in the storage shared with domain data.
+// It is also the LanguageStore of the localizer.
+type Store struct {
+	db storage.Store
+}
+
+func NewStore(db storage.Store) *Store {
+	return &Store{db: db}
+}
+
+func key(userID int64) string {
+	return strconv.FormatInt(userID, 10)
+}
+
+// Get returns the settings of the user, unknown users get the defaults.
+func (s *Store) Get(userID int64) (Settings, error) {
+	var settings Settings
+	err := s.db.View(func(tx storage.Tx) error {
+		_, err := tx.Get(bucket, key(userID), &settings)
+
+		return err
+	})
+
+	return settings, err
+}
+
+// Update changes the settings of the user in a transaction, nothing is saved if fn fails.
+func (s *Store) Update(userID int64, fn func(settings *Settings) error) error {
+	return s.db.Update(func(tx storage.Tx) error {
+		var settings Settings
+		if _, err := tx.Get(bucket, key(userID), &settings); err != nil {
+			return err
+		}
+		if err := fn(&settings); err != nil {
+			return err
+		}
+
+		return tx.Put(bucket, key(userID), settings)
+	})
+}
+
+// Subscribers returns IDs of users who opted in to the topic.
+func (s *Store) Subscribers(topic string) ([]int64, error) {
+	var userIDs []int64
+	err := s.db.View(func(tx storage.Tx) error {
+		keys, err := tx.Keys(bucket)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			var settings Settings
+			if _, err := tx.Get(bucket, k, &settings); err != nil {
+				return err
+			}
+			if !settings.Notify(topic) {
+				continue
+			}
+			if userID, err := strconv.ParseInt(k, 10, 64); err == nil {
+				userIDs = append(userIDs, userID)
+			}
+		}
+
+		return nil
+	})
+
+	return userIDs, err
+}
+
+func (s *Store) Language(userID int64) (string, bool) {
+	settings, err := s.Get(userID)
+	if err != nil || settings.Language == "" {
+		return "", false
+	}
+
+	return settings.Language, true
+}
+
+func (s *Store) SetLanguage(userID int64, lang string) error {
+	return s.Update(userID, func(settings *Settings) error {
+		settings.Language = lang
+
+		return nil
+	})
+}
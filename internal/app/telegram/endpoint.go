@@ -0,0 +1,46 @@
+package telegram
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// NewBotAPI authorizes the bot at the given Bot API server,
+// an empty endpoint means the public api.telegram.org one.
+func NewBotAPI(token, endpoint string) (*tgbotapi.BotAPI, error) {
+	if endpoint == "" {
+		return tgbotapi.NewBotAPI(token)
+	}
+
+	base, err := url.Parse(endpoint)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("malformed Bot API endpoint %q", endpoint)
+	}
+
+	client := &http.Client{
+		Transport: &endpointTransport{base: base, next: http.DefaultTransport},
+	}
+
+	return tgbotapi.NewBotAPIWithClient(token, client)
+}
+
+// endpointTransport redirects requests built for api.telegram.org to another server
+// as the library has no option for it.
+type endpointTransport struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	redirected := req.Clone(req.Context())
+	redirected.URL.Scheme = t.base.Scheme
+	redirected.URL.Host = t.base.Host
+	redirected.URL.Path = strings.TrimSuffix(t.base.Path, "/") + req.URL.Path
+	redirected.Host = t.base.Host
+
+	return t.next.RoundTrip(redirected)
+}
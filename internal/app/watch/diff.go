@@ -0,0 +1,119 @@
+package watch
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/app/render"
+)
+
+// Change is a field which differs between two versions of an entity, empty values mean the field was unset.
+type Change struct {
+	Field         string
+	Before, After string
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// Diff compares two values of the same struct type field by field.
+// Fields are named by their JSON names, nested structs are flattened like "quote.premium".
+func Diff(before, after interface{}) []Change {
+	var fields []string
+	old := make(map[string]string)
+	flatten(reflect.ValueOf(before), "", old, &fields)
+	updated := make(map[string]string)
+	flatten(reflect.ValueOf(after), "", updated, nil)
+
+	var changes []Change
+	for _, field := range fields {
+		if old[field] != updated[field] {
+			changes = append(changes, Change{Field: field, Before: old[field], After: updated[field]})
+		}
+	}
+
+	return changes
+}
+
+func flatten(v reflect.Value, prefix string, out map[string]string, fields *[]string) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+		} else {
+			v = v.Elem()
+		}
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		// the ID never changes and the version changes with every update, neither is worth a notification
+		if name == "" || name == "id" || name == "version" {
+			continue
+		}
+		name = prefix + name
+
+		value := v.Field(i)
+		if isNested(value.Type()) {
+			flatten(value, name+".", out, fields)
+			continue
+		}
+
+		out[name] = format(value)
+		if fields != nil {
+			*fields = append(*fields, name)
+		}
+	}
+}
+
+func isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && t != timeType && !t.Implements(stringerType)
+}
+
+func jsonName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return strings.ToLower(field.Name)
+	default:
+		return name
+	}
+}
+
+// format shows zero values as empty strings, slices of structs are shown by their length.
+func format(v reflect.Value) string {
+	if v.IsZero() {
+		return ""
+	}
+
+	switch {
+	case v.Type() == timeType:
+		return v.Interface().(time.Time).Format(render.DateLayout)
+	case v.Type().Implements(stringerType):
+		return v.Interface().(fmt.Stringer).String()
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		return fmt.Sprintf("[%d]", v.Len())
+	case v.Kind() == reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = format(v.Index(i))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,97 @@
+package watch
+
+import (
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/sender"
+	"github.com/ozonmp/omp-bot/internal/app/settings"
+	"github.com/ozonmp/omp-bot/internal/events"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+)
+
+// CarEntity is the name cars are watched under.
+const CarEntity = "insurance.car"
+
+// Notifier tells the watching chats about changes of the entities, subscribe Handle to the event bus.
+// Private chats are skipped while their users have change notifications turned off in /settings.
+type Notifier struct {
+	bot       sender.Sender
+	store     *Store
+	settings  *settings.Store
+	localizer *i18n.Localizer
+}
+
+func NewNotifier(bot sender.Sender, store *Store, userSettings *settings.Store, localizer *i18n.Localizer) *Notifier {
+	return &Notifier{bot: bot, store: store, settings: userSettings, localizer: localizer}
+}
+
+func (n *Notifier) Handle(event events.Event) {
+	switch e := event.(type) {
+	case carService.CarUpdated:
+		changes := Diff(e.Before, e.After)
+		if len(changes) == 0 {
+			return
+		}
+		n.notify(CarEntity, e.After.ID, func(p i18n.Printer) string {
+			lines := []string{p.T("watch.car.updated", e.After.ID, e.After.Title)}
+			for _, change := range changes {
+				lines = append(lines, p.T("watch.change", change.Field, orDash(change.Before), orDash(change.After)))
+			}
+
+			return strings.Join(lines, "\n")
+		})
+	case carService.CarRemoved:
+		n.notify(CarEntity, e.Car.ID, func(p i18n.Printer) string {
+			return p.T("watch.car.removed", e.Car.ID, e.Car.Title)
+		})
+		if err := n.store.Forget(CarEntity, e.Car.ID); err != nil {
+			log.Printf("Notifier: cannot forget watchers of car %d - %v", e.Car.ID, err)
+		}
+	}
+}
+
+func (n *Notifier) notify(entity string, id uint64, text func(p i18n.Printer) string) {
+	chats, err := n.store.Watchers(entity, id)
+	if err != nil {
+		log.Printf("Notifier: cannot load watchers of %s %d - %v", entity, id, err)
+		return
+	}
+
+	for _, chatID := range chats {
+		if !n.wanted(chatID) {
+			continue
+		}
+
+		_, err := n.bot.Send(tgbotapi.NewMessage(chatID, text(n.localizer.ForChat(chatID))))
+		if err != nil {
+			log.Printf("Notifier: error sending notification to chat %d - %v", chatID, err)
+		}
+	}
+}
+
+// wanted checks the settings of the user for private chats, whose IDs are the IDs of their users.
+// Group chats are always notified, the watch is shared by their members.
+func (n *Notifier) wanted(chatID int64) bool {
+	if chatID <= 0 {
+		return true
+	}
+
+	userSettings, err := n.settings.Get(chatID)
+	if err != nil {
+		log.Printf("Notifier: cannot load settings of user %d - %v", chatID, err)
+		return true
+	}
+
+	return userSettings.Notify(settings.NotifyChanges)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "—"
+	}
+
+	return value
+}
@@ -0,0 +1,98 @@
+package watch
+
+import (
+	"fmt"
+
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+const bucket = "watch"
+
+// Store keeps the chats watching entities, entities are named like "insurance.car".
+type Store struct {
+	db storage.Store
+}
+
+func NewStore(db storage.Store) *Store {
+	return &Store{db: db}
+}
+
+func key(entity string, id uint64) string {
+	return fmt.Sprintf("%s/%020d", entity, id)
+}
+
+// Watch subscribes the chat, it reports false if the chat is already watching.
+func (s *Store) Watch(entity string, id uint64, chatID int64) (bool, error) {
+	added := false
+	err := s.db.Update(func(tx storage.Tx) error {
+		var chats []int64
+		if _, err := tx.Get(bucket, key(entity, id), &chats); err != nil {
+			return err
+		}
+		if contains(chats, chatID) {
+			return nil
+		}
+
+		added = true
+		return tx.Put(bucket, key(entity, id), append(chats, chatID))
+	})
+
+	return added, err
+}
+
+// Unwatch unsubscribes the chat, it reports false if the chat was not watching.
+func (s *Store) Unwatch(entity string, id uint64, chatID int64) (bool, error) {
+	removed := false
+	err := s.db.Update(func(tx storage.Tx) error {
+		var chats []int64
+		if _, err := tx.Get(bucket, key(entity, id), &chats); err != nil {
+			return err
+		}
+
+		kept := chats[:0]
+		for _, chat := range chats {
+			if chat != chatID {
+				kept = append(kept, chat)
+			}
+		}
+		if len(kept) == len(chats) {
+			return nil
+		}
+
+		removed = true
+		if len(kept) == 0 {
+			return tx.Delete(bucket, key(entity, id))
+		}
+		return tx.Put(bucket, key(entity, id), kept)
+	})
+
+	return removed, err
+}
+
+func (s *Store) Watchers(entity string, id uint64) ([]int64, error) {
+	var chats []int64
+	err := s.db.View(func(tx storage.Tx) error {
+		_, err := tx.Get(bucket, key(entity, id), &chats)
+
+		return err
+	})
+
+	return chats, err
+}
+
+// Forget drops all watchers of a removed entity.
+func (s *Store) Forget(entity string, id uint64) error {
+	return s.db.Update(func(tx storage.Tx) error {
+		return tx.Delete(bucket, key(entity, id))
+	})
+}
+
+func contains(chats []int64, chatID int64) bool {
+	for _, chat := range chats {
+		if chat == chatID {
+			return true
+		}
+	}
+
+	return false
+}
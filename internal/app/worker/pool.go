@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"errors"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+var ErrQueueClosed = errors.New("worker queue is closed")
+
+type Handler interface {
+	HandleUpdate(update tgbotapi.Update)
+}
+
+// Pool processes updates with a fixed number of workers reading from a bounded queue.
+type Pool struct {
+	handler Handler
+	queue   chan tgbotapi.Update
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewPool(handler Handler, workers, queueSize int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &Pool{
+		handler: handler,
+		queue:   make(chan tgbotapi.Update, queueSize),
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for update := range p.queue {
+		p.handler.HandleUpdate(update)
+	}
+}
+
+// Submit blocks until the update is queued.
+func (p *Pool) Submit(update tgbotapi.Update) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrQueueClosed
+	}
+	p.queue <- update
+
+	return nil
+}
+
+// Len returns the number of updates waiting for a worker.
+func (p *Pool) Len() int {
+	return len(p.queue)
+}
+
+func (p *Pool) Cap() int {
+	return cap(p.queue)
+}
+
+// Saturation returns the queue fill ratio in the range [0, 1].
+func (p *Pool) Saturation() float64 {
+	if p.Cap() == 0 {
+		return 0
+	}
+
+	return float64(p.Len()) / float64(p.Cap())
+}
+
+// Close stops accepting updates and waits for queued ones to be handled.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
@@ -0,0 +1,139 @@
+// Package cache is a size-bounded LRU cache with expiring entries for the caching decorators of the services.
+package cache
+
+import (
+	"container/list"
+	"expvar"
+	"sync"
+	"time"
+)
+
+// metrics counts hits, misses, expirations and evictions as "<cache name>.<event>".
+var metrics = expvar.NewMap("cache")
+
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// LRU drops the least recently used entry when it is full. Values are returned as they were added,
+// callers copy them if they are mutable.
+//
+// Generations protect from caching stale values: read Generation before loading a value and pass it to Add,
+// the value is dropped if the cache was invalidated in between.
+type LRU struct {
+	name string
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List
+	generation uint64
+}
+
+// New returns a cache keeping up to size entries for ttl, the name is used in metrics.
+func New(name string, size int, ttl time.Duration) *LRU {
+	return &LRU{
+		name:  name,
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+func (c *LRU) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.items[key]
+	if !found {
+		metrics.Add(c.name+".misses", 1)
+		return nil, false
+	}
+
+	e := element.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.remove(element)
+		metrics.Add(c.name+".expired", 1)
+		metrics.Add(c.name+".misses", 1)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	metrics.Add(c.name+".hits", 1)
+
+	return e.value, true
+}
+
+// Generation changes with every invalidation.
+func (c *LRU) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// Add stores the value unless the cache was invalidated since the generation was read, it reports whether
+// the value is stored.
+func (c *LRU) Add(key string, value interface{}, generation uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation || c.size <= 0 {
+		return false
+	}
+
+	expires := c.now().Add(c.ttl)
+	if element, found := c.items[key]; found {
+		e := element.Value.(*entry)
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(element)
+		return true
+	}
+
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		metrics.Add(c.name+".evictions", 1)
+	}
+
+	return true
+}
+
+// Remove invalidates the entry.
+func (c *LRU) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if element, found := c.items[key]; found {
+		c.remove(element)
+	}
+}
+
+// Purge invalidates all entries.
+func (c *LRU) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*entry).key)
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// clock is a fake time source moved by the tests.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestLRU(size int, ttl time.Duration) (*LRU, *clock) {
+	c := &clock{now: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	lru := New("test", size, ttl)
+	lru.now = c.Now
+
+	return lru, c
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	lru, _ := newTestLRU(2, time.Minute)
+
+	lru.Add("a", 1, lru.Generation())
+	lru.Add("b", 2, lru.Generation())
+	if _, found := lru.Get("a"); !found {
+		t.Fatal("a is not cached")
+	}
+	// b is the least recently used now
+	lru.Add("c", 3, lru.Generation())
+
+	if _, found := lru.Get("b"); found {
+		t.Error("b is kept over the size")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if value, found := lru.Get(key); !found || value != want {
+			t.Errorf("Get(%q) = %v, %v, want %d", key, value, found, want)
+		}
+	}
+	if lru.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", lru.Len())
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	lru, clock := newTestLRU(10, time.Minute)
+
+	lru.Add("a", 1, lru.Generation())
+	clock.now = clock.now.Add(time.Minute - time.Nanosecond)
+	if _, found := lru.Get("a"); !found {
+		t.Fatal("a expired before its TTL")
+	}
+
+	// a hit does not prolong the entry, adding it again does
+	clock.now = clock.now.Add(time.Nanosecond)
+	if _, found := lru.Get("a"); found {
+		t.Error("a is returned after its TTL")
+	}
+	if lru.Len() != 0 {
+		t.Errorf("expired entry is kept, Len() = %d", lru.Len())
+	}
+
+	lru.Add("a", 2, lru.Generation())
+	clock.now = clock.now.Add(30 * time.Second)
+	lru.Add("a", 3, lru.Generation())
+	clock.now = clock.now.Add(45 * time.Second)
+	if value, found := lru.Get("a"); !found || value != 3 {
+		t.Errorf("Get(a) = %v, %v, want the value added again", value, found)
+	}
+}
+
+func TestLRUDropsValuesLoadedBeforeInvalidation(t *testing.T) {
+	lru, _ := newTestLRU(10, time.Minute)
+
+	// a reader loads the value, meanwhile a writer changes it and invalidates the key
+	generation := lru.Generation()
+	lru.Remove("a")
+	if lru.Add("a", "stale", generation) {
+		t.Error("value loaded before Remove is stored")
+	}
+
+	generation = lru.Generation()
+	lru.Purge()
+	if lru.Add("b", "stale", generation) {
+		t.Error("value loaded before Purge is stored")
+	}
+
+	if !lru.Add("a", "fresh", lru.Generation()) {
+		t.Error("value loaded after the invalidation is not stored")
+	}
+	if value, found := lru.Get("a"); !found || value != "fresh" {
+		t.Errorf("Get(a) = %v, %v, want fresh", value, found)
+	}
+}
+
+func TestLRUPurge(t *testing.T) {
+	lru, _ := newTestLRU(10, time.Minute)
+
+	lru.Add("a", 1, lru.Generation())
+	lru.Add("b", 2, lru.Generation())
+	lru.Purge()
+
+	if lru.Len() != 0 {
+		t.Errorf("Len() = %d after Purge", lru.Len())
+	}
+	if _, found := lru.Get("a"); found {
+		t.Error("a is kept after Purge")
+	}
+}
+
+func TestLRUWithoutSizeKeepsNothing(t *testing.T) {
+	lru, _ := newTestLRU(0, time.Minute)
+
+	if lru.Add("a", 1, lru.Generation()) {
+		t.Error("cache of size 0 stores a value")
+	}
+}
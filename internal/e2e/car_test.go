@@ -0,0 +1,124 @@
+package e2e
+
+import (
+	"html"
+	"regexp"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+)
+
+func TestCarListPages(t *testing.T) {
+	New(t).
+		UserSends("/list__insurance__car 2").
+		ExpectReply(`(?s)Cars.*1</code> Toyota\n<code>2</code> Nissan$`).
+		ExpectKeyboard("Next page").
+		PressButton("Next page").
+		ExpectReply(`(?s)Cars.*3</code> Infinity\n<code>4</code> Mazda$`)
+}
+
+func TestCarGet(t *testing.T) {
+	New(t).
+		UserSends("/get__insurance__car 2").
+		ExpectReply(`Car #2</b>\nTitle: Nissan\nVersion: 1`).
+		UserSends("/get__insurance__car 100").
+		ExpectReply(`100`)
+}
+
+func TestCarEditConflict(t *testing.T) {
+	s := New(t)
+
+	s.UserSends("/edit__insurance__car 2 title=Nissan_Leaf version=1").
+		ExpectReply(`Successfully edited car with id 2`).
+		UserSends("/edit__insurance__car 2 make=Nissan version=1").
+		ExpectReply(`(?s)changed by someone else.*Title: Nissan_Leaf\nVersion: 2`).
+		ExpectKeyboard("Apply my changes again").
+		PressButton("Apply my changes again").
+		ExpectReply(`Successfully edited car with id 2`).
+		UserSends("/get__insurance__car 2").
+		ExpectReply(`Title: Nissan_Leaf\nMake: Nissan\n(.*\n)?Version: 3`)
+}
+
+// TestCarEditFromStaleCard has two agents edit from cards showing the same version,
+// the edit sent second must not overwrite the first one.
+func TestCarEditFromStaleCard(t *testing.T) {
+	first := New(t)
+	second := first.As(tgbotapi.User{ID: 1002, FirstName: "Second", LanguageCode: "en"})
+
+	first.UserSends("/get__insurance__car 2").
+		ExpectReply(`Title: Nissan\nVersion: 1`).
+		ExpectKeyboard("Edit")
+	second.UserSends("/get__insurance__car 2").
+		ExpectReply(`Title: Nissan\nVersion: 1`)
+
+	first.PressButton("Edit").
+		ExpectReply(`version 1 of car 2`)
+	second.PressButton("Edit").
+		ExpectReply(`version 1 of car 2`)
+	firstCommand, secondCommand := offeredCommand(t, first), offeredCommand(t, second)
+	if want := `/edit__insurance__car 2 version=1`; firstCommand != want {
+		t.Fatalf("offered command %q, want %q", firstCommand, want)
+	}
+
+	first.UserSends(firstCommand + " title=Leaf year=2020").
+		ExpectReply(`Successfully edited car with id 2`)
+	second.UserSends(secondCommand + " make=Nissan").
+		ExpectReply(`(?s)changed by someone else.*Title: Leaf\nYear: 2020\nVersion: 2`).
+		PressButton("Apply my changes again").
+		ExpectReply(`Successfully edited car with id 2`).
+		UserSends("/get__insurance__car 2").
+		ExpectReply(`Title: Leaf\nMake: Nissan\nYear: 2020\nVersion: 3`)
+
+	// a typed edit without a version is made over the current one
+	second.UserSends("/edit__insurance__car 2 Nissan Leaf").
+		ExpectReply(`Successfully edited car with id 2`).
+		UserSends("/get__insurance__car 2").
+		ExpectReply(`Title: Nissan Leaf\nMake: Nissan\nYear: 2020\nVersion: 4`)
+}
+
+var codePattern = regexp.MustCompile(`<code>(.*)</code>`)
+
+// offeredCommand returns the command the edit button has offered in the last reply.
+func offeredCommand(t *testing.T, s *Scenario) string {
+	t.Helper()
+
+	match := codePattern.FindStringSubmatch(s.last.Text)
+	if match == nil {
+		t.Fatalf("no command in %q", s.last.Text)
+	}
+
+	return html.UnescapeString(match[1])
+}
+
+func TestCarAliases(t *testing.T) {
+	aliases, err := path.ParseAliases("cars=list__insurance__car,car=get__insurance__car")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	New(t, WithAliases(aliases)).
+		UserSends("/cars 1").
+		ExpectReply(`(?s)Cars.*1</code> Toyota$`).
+		UserSends("/car 3").
+		ExpectReply(`Car #3</b>\nTitle: Infinity`)
+}
+
+func TestStartDeepLink(t *testing.T) {
+	New(t).
+		UserSends("/start get__insurance__car-3").
+		ExpectReply(`Car #3</b>\nTitle: Infinity`)
+}
+
+// TestCarQuoteSavedOverItsVersion saves a quote calculated before the car was edited.
+func TestCarQuoteSavedOverItsVersion(t *testing.T) {
+	New(t).
+		UserSends("/quote__insurance__car 1 30 msk make=toyota year=2015").
+		ExpectReply(`Toyota`).
+		UserSends("/edit__insurance__car 1 make=Lexus").
+		ExpectReply(`Successfully edited car with id 1`).
+		PressButton("Save to the car").
+		ExpectReply(`car 1 has been changed since the quote was calculated`).
+		UserSends("/get__insurance__car 1").
+		ExpectReply(`Title: Toyota\nMake: Lexus\nVersion: 2$`)
+}
@@ -0,0 +1,38 @@
+package e2e
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+)
+
+func TestClaimApprovedByAgent(t *testing.T) {
+	roles := auth.NewStaticResolver(auth.RoleUser)
+	if err := roles.Grant(auth.RoleAgent, "2002"); err != nil {
+		t.Fatal(err)
+	}
+	user := New(t, WithRoles(roles))
+	agent := user.As(tgbotapi.User{ID: 2002, FirstName: "Agent", LanguageCode: "en"})
+
+	user.UserSends("/new__insurance__claim 1 5000 broken door").
+		ExpectReply(`Successfully filed claim with id 1`).
+		UserSends("/get__insurance__claim 1").
+		ExpectReply(`Status: filed`).
+		PressButton("Start review").
+		ExpectReply(`Access denied: /status__insurance__claim requires the agent role`)
+
+	agent.UserSends("/get__insurance__claim 1").
+		ExpectReply(`Status: filed`).
+		PressButton("Start review").
+		ExpectReply(`Status: under review`).
+		ExpectKeyboard("Approve", "Reject").
+		PressButton("Approve").
+		ExpectReply(`Status: approved`).
+		ExpectKeyboard("Mark as paid")
+
+	user.UserSends("/status__insurance__claim 1 paid").
+		ExpectReply(`Access denied`).
+		UserSends("/get__insurance__claim 1").
+		ExpectReply(`Status: approved`)
+}
@@ -0,0 +1,3 @@
+// Package e2e holds the end-to-end scenario tests: the whole bot runs against a fake Bot API server.
+// The fake server and the scenario DSL are in the _test.go files, so they are not built into the bot.
+package e2e
@@ -0,0 +1,453 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// maxPollWait limits long polling so that closing the server is not delayed.
+const maxPollWait = time.Second
+
+// Sent is a request of the bot which produced or changed a message.
+type Sent struct {
+	Method    string
+	ChatID    int64
+	MessageID int
+	Text      string
+	ParseMode string
+	Keyboard  *tgbotapi.InlineKeyboardMarkup
+	// FileName is set for documents, for photos it is the file ID
+	FileName string
+}
+
+// CallbackAnswer is a recorded answerCallbackQuery request.
+type CallbackAnswer struct {
+	CallbackQueryID string
+	Text            string
+	ShowAlert       bool
+}
+
+// BotCommand is an entry of the command menu set with setMyCommands.
+type BotCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// FakeServer is an in-process Bot API server, the bot is pointed at it
+// with telegram.NewBotAPI(token, server.URL()).
+type FakeServer struct {
+	Bot tgbotapi.User
+
+	server *httptest.Server
+
+	mu            sync.Mutex
+	updates       []tgbotapi.Update
+	newUpdate     chan struct{}
+	lastUpdateID  int
+	lastMessageID int
+	sent          []Sent
+	answers       []CallbackAnswer
+	commands      map[string][]BotCommand
+	calls         map[string]int
+	failures      map[string][]error
+}
+
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{
+		Bot:       tgbotapi.User{ID: 1, FirstName: "Test", UserName: "test_bot"},
+		newUpdate: make(chan struct{}),
+		commands:  make(map[string][]BotCommand),
+		calls:     make(map[string]int),
+		failures:  make(map[string][]error),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+func (s *FakeServer) URL() string {
+	return s.server.URL
+}
+
+func (s *FakeServer) Close() {
+	s.server.Close()
+}
+
+// Push makes the update available to getUpdates, its ID is assigned by the server.
+func (s *FakeServer) Push(update tgbotapi.Update) tgbotapi.Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastUpdateID++
+	update.UpdateID = s.lastUpdateID
+	s.updates = append(s.updates, update)
+
+	close(s.newUpdate)
+	s.newUpdate = make(chan struct{})
+
+	return update
+}
+
+// NextMessageID reserves an ID for a message sent by a user.
+func (s *FakeServer) NextMessageID() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastMessageID++
+
+	return s.lastMessageID
+}
+
+// Sent returns all recorded messages in the order they were received.
+func (s *FakeServer) Sent() []Sent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Sent(nil), s.sent...)
+}
+
+func (s *FakeServer) CallbackAnswers() []CallbackAnswer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]CallbackAnswer(nil), s.answers...)
+}
+
+// Commands returns the command menu published for the scope, e.g. "all_private_chats",
+// menus of a language are published for scopes like "all_private_chats/ru".
+func (s *FakeServer) Commands(scope string) []BotCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]BotCommand(nil), s.commands[scope]...)
+}
+
+// Calls returns the number of requests made to the Bot API method.
+func (s *FakeServer) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[method]
+}
+
+// FailNext makes the next requests to the method fail with the given errors,
+// tgbotapi.Error values keep their retry_after parameter.
+func (s *FakeServer) FailNext(method string, errs ...error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[method] = append(s.failures[method], errs...)
+}
+
+type apiResponse struct {
+	Ok          bool                         `json:"ok"`
+	Result      interface{}                  `json:"result,omitempty"`
+	ErrorCode   int                          `json:"error_code,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	Parameters  *tgbotapi.ResponseParameters `json:"parameters,omitempty"`
+}
+
+func (s *FakeServer) serve(w http.ResponseWriter, r *http.Request) {
+	// paths look like /bot<token>/<method>
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "bot") {
+		http.NotFound(w, r)
+		return
+	}
+	method := parts[1]
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			s.reply(w, apiResponse{ErrorCode: http.StatusBadRequest, Description: err.Error()})
+			return
+		}
+	} else if err := r.ParseForm(); err != nil {
+		s.reply(w, apiResponse{ErrorCode: http.StatusBadRequest, Description: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	s.calls[method]++
+	var failure error
+	if queued := s.failures[method]; len(queued) > 0 {
+		failure, s.failures[method] = queued[0], queued[1:]
+	}
+	s.mu.Unlock()
+
+	if failure != nil {
+		resp := apiResponse{ErrorCode: http.StatusBadRequest, Description: failure.Error()}
+		if apiErr, ok := failure.(tgbotapi.Error); ok && apiErr.RetryAfter > 0 {
+			resp.ErrorCode = http.StatusTooManyRequests
+			resp.Parameters = &apiErr.ResponseParameters
+		}
+		s.reply(w, resp)
+		return
+	}
+
+	result, err := s.call(method, r)
+	if err != nil {
+		s.reply(w, apiResponse{ErrorCode: http.StatusBadRequest, Description: "Bad Request: " + err.Error()})
+		return
+	}
+	s.reply(w, apiResponse{Ok: true, Result: result})
+}
+
+func (s *FakeServer) reply(w http.ResponseWriter, resp apiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("FakeServer: error writing response - %v", err)
+	}
+}
+
+func (s *FakeServer) call(method string, r *http.Request) (interface{}, error) {
+	switch method {
+	case "getMe":
+		return s.Bot, nil
+	case "getUpdates":
+		return s.getUpdates(r)
+	case "sendMessage":
+		return s.sendMessage(r)
+	case "editMessageText":
+		return s.editMessageText(r)
+	case "answerCallbackQuery":
+		return s.answerCallbackQuery(r)
+	case "sendDocument":
+		return s.sendDocument(r)
+	case "sendPhoto":
+		return s.sendPhoto(r)
+	case "setMyCommands":
+		return s.setMyCommands(r)
+	default:
+		return nil, fmt.Errorf("method %s is not supported by the fake server", method)
+	}
+}
+
+func (s *FakeServer) getUpdates(r *http.Request) (interface{}, error) {
+	offset, _ := strconv.Atoi(r.FormValue("offset"))
+	timeout, _ := strconv.Atoi(r.FormValue("timeout"))
+	wait := time.Duration(timeout) * time.Second
+	if wait > maxPollWait {
+		wait = maxPollWait
+	}
+	deadline := time.After(wait)
+
+	for {
+		s.mu.Lock()
+		var pending []tgbotapi.Update
+		for _, update := range s.updates {
+			if update.UpdateID >= offset {
+				pending = append(pending, update)
+			}
+		}
+		newUpdate := s.newUpdate
+		s.mu.Unlock()
+
+		if len(pending) > 0 {
+			return pending, nil
+		}
+
+		select {
+		case <-newUpdate:
+		case <-deadline:
+			return []tgbotapi.Update{}, nil
+		case <-r.Context().Done():
+			return []tgbotapi.Update{}, nil
+		}
+	}
+}
+
+func chatID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.FormValue("chat_id"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("chat_id is required")
+	}
+
+	return id, nil
+}
+
+func keyboard(r *http.Request) (*tgbotapi.InlineKeyboardMarkup, error) {
+	raw := r.FormValue("reply_markup")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var markup tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(raw), &markup); err != nil {
+		return nil, fmt.Errorf("malformed reply_markup: %v", err)
+	}
+	if len(markup.InlineKeyboard) == 0 {
+		// reply keyboards and keyboard removals are not recorded
+		return nil, nil
+	}
+
+	return &markup, nil
+}
+
+func (s *FakeServer) record(sent Sent) tgbotapi.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sent.MessageID == 0 {
+		s.lastMessageID++
+		sent.MessageID = s.lastMessageID
+	}
+	s.sent = append(s.sent, sent)
+
+	return tgbotapi.Message{
+		MessageID: sent.MessageID,
+		From:      &s.Bot,
+		Date:      int(time.Now().Unix()),
+		Chat:      &tgbotapi.Chat{ID: sent.ChatID, Type: chatType(sent.ChatID)},
+		Text:      sent.Text,
+	}
+}
+
+func (s *FakeServer) sendMessage(r *http.Request) (interface{}, error) {
+	id, err := chatID(r)
+	if err != nil {
+		return nil, err
+	}
+	text := r.FormValue("text")
+	if text == "" {
+		return nil, fmt.Errorf("message text is empty")
+	}
+	markup, err := keyboard(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.record(Sent{
+		Method:    "sendMessage",
+		ChatID:    id,
+		Text:      text,
+		ParseMode: r.FormValue("parse_mode"),
+		Keyboard:  markup,
+	}), nil
+}
+
+func (s *FakeServer) editMessageText(r *http.Request) (interface{}, error) {
+	id, err := chatID(r)
+	if err != nil {
+		return nil, err
+	}
+	messageID, err := strconv.Atoi(r.FormValue("message_id"))
+	if err != nil {
+		return nil, fmt.Errorf("message_id is required")
+	}
+	markup, err := keyboard(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.record(Sent{
+		Method:    "editMessageText",
+		ChatID:    id,
+		MessageID: messageID,
+		Text:      r.FormValue("text"),
+		ParseMode: r.FormValue("parse_mode"),
+		Keyboard:  markup,
+	}), nil
+}
+
+func (s *FakeServer) answerCallbackQuery(r *http.Request) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.answers = append(s.answers, CallbackAnswer{
+		CallbackQueryID: r.FormValue("callback_query_id"),
+		Text:            r.FormValue("text"),
+		ShowAlert:       r.FormValue("show_alert") == "true",
+	})
+
+	return true, nil
+}
+
+func (s *FakeServer) sendDocument(r *http.Request) (interface{}, error) {
+	id, err := chatID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	name := r.FormValue("document")
+	if r.MultipartForm != nil {
+		if files := r.MultipartForm.File["document"]; len(files) > 0 {
+			name = files[0].Filename
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("document is required")
+	}
+
+	return s.record(Sent{
+		Method:    "sendDocument",
+		ChatID:    id,
+		Text:      r.FormValue("caption"),
+		ParseMode: r.FormValue("parse_mode"),
+		FileName:  name,
+	}), nil
+}
+
+// sendPhoto records photos shared by file ID, uploads are not supported.
+func (s *FakeServer) sendPhoto(r *http.Request) (interface{}, error) {
+	id, err := chatID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	fileID := r.FormValue("photo")
+	if fileID == "" {
+		return nil, fmt.Errorf("photo is required")
+	}
+
+	return s.record(Sent{
+		Method:    "sendPhoto",
+		ChatID:    id,
+		Text:      r.FormValue("caption"),
+		ParseMode: r.FormValue("parse_mode"),
+		FileName:  fileID,
+	}), nil
+}
+
+func chatType(chatID int64) string {
+	if chatID < 0 {
+		return "group"
+	}
+
+	return "private"
+}
+
+func (s *FakeServer) setMyCommands(r *http.Request) (interface{}, error) {
+	var commands []BotCommand
+	if err := json.Unmarshal([]byte(r.FormValue("commands")), &commands); err != nil {
+		return nil, fmt.Errorf("malformed commands: %v", err)
+	}
+
+	scope := struct {
+		Type string `json:"type"`
+	}{Type: "default"}
+	if raw := r.FormValue("scope"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &scope); err != nil {
+			return nil, fmt.Errorf("malformed scope: %v", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := scope.Type
+	if lang := r.FormValue("language_code"); lang != "" {
+		key += "/" + lang
+	}
+	s.commands[key] = commands
+
+	return true, nil
+}
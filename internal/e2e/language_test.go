@@ -0,0 +1,20 @@
+package e2e
+
+import "testing"
+
+func TestLanguageSwitch(t *testing.T) {
+	New(t).
+		UserSends("/get__insurance__car 1").
+		ExpectReply(`Car #1</b>\nTitle: Toyota`).
+		UserSends("/language ru").
+		ExpectReply(`Выбран язык: Русский`).
+		UserSends("/get__insurance__car 1").
+		ExpectReply(`Автомобиль №1</b>\nНазвание: Toyota`).
+		UserSends("/language").
+		ExpectReply(`Русский`).
+		ExpectKeyboard("English").
+		PressButton("English").
+		ExpectReply(`Language is set to English`).
+		UserSends("/get__insurance__car 1").
+		ExpectReply(`Car #1</b>\nTitle: Toyota`)
+}
@@ -0,0 +1,413 @@
+package e2e
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/ozonmp/omp-bot/internal/app/auth"
+	"github.com/ozonmp/omp-bot/internal/app/commands/insurance"
+	"github.com/ozonmp/omp-bot/internal/app/commands/meta"
+	"github.com/ozonmp/omp-bot/internal/app/i18n"
+	"github.com/ozonmp/omp-bot/internal/app/path"
+	"github.com/ozonmp/omp-bot/internal/app/router"
+	"github.com/ozonmp/omp-bot/internal/app/settings"
+	"github.com/ozonmp/omp-bot/internal/app/telegram"
+	"github.com/ozonmp/omp-bot/internal/app/watch"
+	"github.com/ozonmp/omp-bot/internal/events"
+	"github.com/ozonmp/omp-bot/internal/events/outbox"
+	carService "github.com/ozonmp/omp-bot/internal/service/insurance/car"
+	claimService "github.com/ozonmp/omp-bot/internal/service/insurance/claim"
+	policyService "github.com/ozonmp/omp-bot/internal/service/insurance/policy"
+	"github.com/ozonmp/omp-bot/internal/service/insurance/rating"
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+// DefaultTimeout is how long a scenario waits for the bot to reply.
+const DefaultTimeout = 5 * time.Second
+
+type config struct {
+	services insurance.Services
+	roles    *auth.StaticResolver
+	aliases  path.Aliases
+	user     tgbotapi.User
+	chat     tgbotapi.Chat
+	timeout  time.Duration
+}
+
+type Option func(*config)
+
+// WithCarService replaces the sample cars, changes made through the service reach the watchers
+// only if the service records them to the outbox itself.
+func WithCarService(service carService.CarService) Option {
+	return func(c *config) {
+		c.services.Car = service
+	}
+}
+
+func WithClaimService(service claimService.ClaimService) Option {
+	return func(c *config) {
+		c.services.Claim = service
+	}
+}
+
+func WithPolicyService(service policyService.PolicyService) Option {
+	return func(c *config) {
+		c.services.Policy = service
+	}
+}
+
+// WithUser changes the user sending messages, the chat is the private one of the user.
+func WithUser(user tgbotapi.User) Option {
+	return func(c *config) {
+		c.user = user
+		c.chat = tgbotapi.Chat{ID: int64(user.ID), Type: "private"}
+	}
+}
+
+// WithGroupChat makes the user write to a group chat.
+func WithGroupChat(chatID int64) Option {
+	return func(c *config) {
+		c.chat = tgbotapi.Chat{ID: chatID, Type: "group", Title: "Test group"}
+	}
+}
+
+// WithRoles replaces the default resolver granting everybody the agent role.
+func WithRoles(roles *auth.StaticResolver) Option {
+	return func(c *config) {
+		c.roles = roles
+	}
+}
+
+func WithAliases(aliases path.Aliases) Option {
+	return func(c *config) {
+		c.aliases = aliases
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// Scenario drives the whole bot through the fake Bot API server:
+//
+//	e2e.New(t).
+//		UserSends("/list__insurance__car 2").
+//		ExpectReply("Toyota").
+//		PressButton("Next page").
+//		ExpectReply("Infinity")
+type Scenario struct {
+	t       testing.TB
+	config  config
+	Server  *FakeServer
+	Bot     *tgbotapi.BotAPI
+	Router  *router.Router
+	bus     *events.Bus
+	relay   *outbox.Relay
+	stop    chan struct{}
+	stopped chan struct{}
+
+	seen int
+	last *Sent
+}
+
+func New(t testing.TB, options ...Option) *Scenario {
+	t.Helper()
+
+	cfg := config{
+		services: insurance.Services{
+			Policy: policyService.NewMemoryPolicyService(),
+			Claim:  claimService.NewMemoryClaimService(),
+			Rating: rating.NewTableEngine(rating.DefaultTables()),
+		},
+		roles:   auth.NewStaticResolver(auth.RoleAgent),
+		timeout: DefaultTimeout,
+	}
+	WithUser(tgbotapi.User{ID: 1001, FirstName: "Tester", UserName: "tester", LanguageCode: "en"})(&cfg)
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	store := storage.NewMemoryStore()
+	userSettings := settings.NewStore(store)
+	localizer := i18n.NewLocalizer(i18n.Default, i18n.SourceLanguage, userSettings)
+	server := NewFakeServer()
+	bot, err := telegram.NewBotAPI("test-token", server.URL())
+	if err != nil {
+		server.Close()
+		t.Fatalf("e2e: cannot connect the bot to the fake server - %v", err)
+	}
+
+	bus := events.NewBus(100)
+	eventOutbox := outbox.New(store)
+	relay := outbox.NewRelay(eventOutbox, outbox.DefaultConfig, outbox.NewBusSink(bus))
+	services := cfg.services
+	if services.Car == nil {
+		// the sample cars are kept like the bot keeps them, so changes reach the watchers through the outbox
+		cars := carService.NewStoredCarService(store, eventOutbox)
+		if err := cars.Seed(carService.SampleCars()...); err != nil {
+			server.Close()
+			t.Fatalf("e2e: cannot seed the sample cars - %v", err)
+		}
+		services.Car = cars
+	}
+	services.Watches = watch.NewStore(store)
+	bus.Subscribe(watch.NewNotifier(bot, services.Watches, userSettings, localizer).Handle)
+	relay.Start()
+
+	s := &Scenario{
+		t:      t,
+		config: cfg,
+		Server: server,
+		Bot:    bot,
+		Router: router.NewRouter(bot, services, localizer, userSettings, router.Config{
+			Roles:       cfg.roles,
+			Aliases:     cfg.aliases,
+			BotUserName: bot.Self.UserName,
+			Answerer:    bot,
+		}),
+		bus:     bus,
+		relay:   relay,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if err := meta.Publish(bot, s.Router.Commands(), cfg.roles.Default, localizer); err != nil {
+		server.Close()
+		t.Fatalf("e2e: cannot publish commands - %v", err)
+	}
+
+	go s.poll()
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// poll mirrors the update loop of cmd/bot.
+func (s *Scenario) poll() {
+	defer close(s.stopped)
+
+	config := tgbotapi.UpdateConfig{Timeout: 1}
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		updates, err := s.Bot.GetUpdates(config)
+		if err != nil {
+			continue
+		}
+		for _, update := range updates {
+			if update.UpdateID >= config.Offset {
+				config.Offset = update.UpdateID + 1
+				s.Router.HandleUpdate(update)
+			}
+		}
+	}
+}
+
+func (s *Scenario) Close() {
+	select {
+	case <-s.stop:
+		return
+	default:
+	}
+
+	close(s.stop)
+	<-s.stopped
+	s.relay.Stop()
+	s.bus.Close()
+	s.Server.Close()
+}
+
+// As returns the scenario of another user writing to the bot from a private chat, e.g. a second agent.
+// The scenarios share the bot, each reads the replies to its own chat.
+func (s *Scenario) As(user tgbotapi.User) *Scenario {
+	other := *s
+	WithUser(user)(&other.config)
+	other.seen, other.last = len(s.Server.Sent()), nil
+
+	return &other
+}
+
+// UserSends delivers a text message of the user, leading slash commands are marked as such.
+func (s *Scenario) UserSends(text string) *Scenario {
+	s.t.Helper()
+
+	s.Server.Push(tgbotapi.Update{Message: s.userMessage(text)})
+
+	return s
+}
+
+// UserRepliesToPhoto delivers a text message replying to a photo with the given file ID.
+func (s *Scenario) UserRepliesToPhoto(fileID, text string) *Scenario {
+	s.t.Helper()
+
+	photo := s.userMessage("")
+	photo.Photo = &[]tgbotapi.PhotoSize{
+		{FileID: fileID + "-thumb", Width: 90, Height: 90},
+		{FileID: fileID, Width: 800, Height: 600},
+	}
+	msg := s.userMessage(text)
+	msg.ReplyToMessage = photo
+
+	s.Server.Push(tgbotapi.Update{Message: msg})
+
+	return s
+}
+
+func (s *Scenario) userMessage(text string) *tgbotapi.Message {
+	msg := &tgbotapi.Message{
+		MessageID: s.Server.NextMessageID(),
+		From:      &s.config.user,
+		Date:      int(time.Now().Unix()),
+		Chat:      &s.config.chat,
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		length := strings.IndexByte(text, ' ')
+		if length < 0 {
+			length = len(text)
+		}
+		msg.Entities = &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: length}}
+	}
+
+	return msg
+}
+
+// PressButton presses the inline button with the given text on the last reply having a keyboard.
+func (s *Scenario) PressButton(text string) *Scenario {
+	s.t.Helper()
+
+	sent := s.Server.Sent()
+	for i := len(sent) - 1; i >= 0; i-- {
+		if sent[i].Keyboard == nil || sent[i].ChatID != s.config.chat.ID {
+			continue
+		}
+		for _, row := range sent[i].Keyboard.InlineKeyboard {
+			for _, button := range row {
+				if button.Text != text || button.CallbackData == nil {
+					continue
+				}
+
+				s.Server.Push(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+					ID:   fmt.Sprintf("callback-%d", s.Server.NextMessageID()),
+					From: &s.config.user,
+					Message: &tgbotapi.Message{
+						MessageID: sent[i].MessageID,
+						From:      &s.Server.Bot,
+						Chat:      &s.config.chat,
+						Text:      sent[i].Text,
+					},
+					Data: *button.CallbackData,
+				}})
+
+				return s
+			}
+		}
+
+		s.t.Fatalf("e2e: the last keyboard has no button %q:\n%s", text, describeKeyboard(sent[i].Keyboard))
+	}
+
+	s.t.Fatalf("e2e: there is no keyboard with button %q", text)
+
+	return s
+}
+
+// ExpectReply waits for the next message to the chat and matches it against the regular expression.
+func (s *Scenario) ExpectReply(pattern string) *Scenario {
+	s.t.Helper()
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		s.t.Fatalf("e2e: malformed pattern %q - %v", pattern, err)
+	}
+
+	sent, ok := s.next(s.config.timeout)
+	if !ok {
+		s.t.Fatalf("e2e: no reply matching %q within %v", pattern, s.config.timeout)
+	}
+	if !re.MatchString(sent.Text) {
+		s.t.Fatalf("e2e: reply %q does not match %q", sent.Text, pattern)
+	}
+
+	return s
+}
+
+// ExpectKeyboard checks that the last reply has a button with the given text.
+func (s *Scenario) ExpectKeyboard(buttons ...string) *Scenario {
+	s.t.Helper()
+
+	if s.last == nil || s.last.Keyboard == nil {
+		s.t.Fatalf("e2e: the last reply has no keyboard")
+	}
+	described := describeKeyboard(s.last.Keyboard)
+	for _, button := range buttons {
+		if !strings.Contains(described, "["+button+"]") {
+			s.t.Fatalf("e2e: the last keyboard has no button %q:\n%s", button, described)
+		}
+	}
+
+	return s
+}
+
+// ExpectNoReply checks that the bot stays silent for a while.
+func (s *Scenario) ExpectNoReply(wait time.Duration) *Scenario {
+	s.t.Helper()
+
+	if sent, ok := s.next(wait); ok {
+		s.t.Fatalf("e2e: unexpected reply %q", sent.Text)
+	}
+
+	return s
+}
+
+// Replies returns the unread replies to the chat and marks them as read.
+func (s *Scenario) Replies() []Sent {
+	var replies []Sent
+	for {
+		sent, ok := s.next(0)
+		if !ok {
+			return replies
+		}
+		replies = append(replies, sent)
+	}
+}
+
+func (s *Scenario) next(wait time.Duration) (Sent, bool) {
+	deadline := time.Now().Add(wait)
+	for {
+		sent := s.Server.Sent()
+		for s.seen < len(sent) {
+			candidate := sent[s.seen]
+			s.seen++
+			if candidate.ChatID == s.config.chat.ID {
+				s.last = &candidate
+				return candidate, true
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			return Sent{}, false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func describeKeyboard(markup *tgbotapi.InlineKeyboardMarkup) string {
+	var b strings.Builder
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			b.WriteString("[" + button.Text + "]")
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
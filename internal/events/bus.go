@@ -0,0 +1,109 @@
+package events
+
+import (
+	"errors"
+	"expvar"
+	"log"
+	"sync"
+)
+
+var (
+	ErrBusClosed = errors.New("event bus is closed")
+
+	eventMetrics = expvar.NewMap("events")
+)
+
+// Event is a fact about a change of the domain, EventName is like "insurance.car.updated".
+type Event interface {
+	EventName() string
+}
+
+type Handler func(event Event)
+
+// Publisher is the side of the bus the services see.
+type Publisher interface {
+	Publish(event Event) error
+}
+
+// Bus delivers events to the subscribers in the order they are published.
+// Handlers are called one at a time from a background goroutine, so publishers never wait for them.
+type Bus struct {
+	queue chan Event
+	done  chan struct{}
+
+	handlersMu sync.Mutex
+	handlers   []Handler
+
+	// mu guards the queue from being closed while an event is queued
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewBus(queueSize int) *Bus {
+	b := &Bus{
+		queue: make(chan Event, queueSize),
+		done:  make(chan struct{}),
+	}
+	go b.dispatch()
+
+	return b
+}
+
+// Subscribe adds the handler for all events, handlers pick the events they need by type.
+func (b *Bus) Subscribe(handler Handler) {
+	b.handlersMu.Lock()
+	defer b.handlersMu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish queues the event, it blocks while the queue is full.
+func (b *Bus) Publish(event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrBusClosed
+	}
+	eventMetrics.Add(event.EventName(), 1)
+	b.queue <- event
+
+	return nil
+}
+
+// Close stops accepting events and waits until the queued ones are handled.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+
+	<-b.done
+}
+
+func (b *Bus) dispatch() {
+	defer close(b.done)
+
+	for event := range b.queue {
+		b.handlersMu.Lock()
+		handlers := b.handlers
+		b.handlersMu.Unlock()
+
+		for _, handler := range handlers {
+			b.handle(handler, event)
+		}
+	}
+}
+
+// handle keeps the bus running when a handler panics.
+func (b *Bus) handle(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Bus: handler of %s panicked - %v", event.EventName(), r)
+		}
+	}()
+
+	handler(event)
+}
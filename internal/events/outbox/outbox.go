@@ -0,0 +1,99 @@
+// Package outbox keeps domain events in the storage next to the data they describe,
+// so an event is recorded if and only if its change is committed.
+// The relay delivers the recorded events to the sinks at least once.
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/events"
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+const (
+	bucket = "outbox"
+	// sequenceBucket is shared with the domain IDs, the outbox keeps its last ID under its bucket name.
+	sequenceBucket = "sequence"
+)
+
+// Envelope is a recorded event. IDs grow with every event, sinks may use them to drop duplicates.
+type Envelope struct {
+	ID         uint64          `json:"id"`
+	Name       string          `json:"name"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// Event decodes the payload into the registered event type.
+func (e Envelope) Event() (events.Event, error) {
+	return events.Decode(e.Name, e.Payload)
+}
+
+// Outbox records events in storage transactions and wakes up the relay.
+type Outbox struct {
+	store storage.Store
+	now   func() time.Time
+	// recorded wakes up the relay, it is never blocked on
+	recorded chan struct{}
+}
+
+func New(store storage.Store) *Outbox {
+	return &Outbox{
+		store:    store,
+		now:      time.Now,
+		recorded: make(chan struct{}, 1),
+	}
+}
+
+// Record adds the event to the outbox within the transaction of the change it describes.
+// Call Notify after the transaction is committed.
+func (o *Outbox) Record(tx storage.Tx, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("cannot encode event %s: %w", event.EventName(), err)
+	}
+
+	var lastID uint64
+	if _, err := tx.Get(sequenceBucket, bucket, &lastID); err != nil {
+		return err
+	}
+	envelope := Envelope{
+		ID:         lastID + 1,
+		Name:       event.EventName(),
+		OccurredAt: o.now(),
+		Payload:    payload,
+	}
+	if err := tx.Put(sequenceBucket, bucket, envelope.ID); err != nil {
+		return err
+	}
+
+	return tx.Put(bucket, envelopeKey(envelope.ID), envelope)
+}
+
+// Publish records the event in its own transaction, it is for services which keep their data elsewhere.
+func (o *Outbox) Publish(event events.Event) error {
+	err := o.store.Update(func(tx storage.Tx) error {
+		return o.Record(tx, event)
+	})
+	if err != nil {
+		return err
+	}
+	o.Notify()
+
+	return nil
+}
+
+// Notify tells the relay there are new events, otherwise it finds them on the next poll.
+func (o *Outbox) Notify() {
+	select {
+	case o.recorded <- struct{}{}:
+	default:
+	}
+}
+
+// envelopeKey pads IDs so keys are sorted like numbers.
+func envelopeKey(id uint64) string {
+	return fmt.Sprintf("%020d", id)
+}
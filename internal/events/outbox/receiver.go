@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+)
+
+// maxEnvelopeSize limits the body of a received envelope.
+const maxEnvelopeSize = 1 << 20
+
+// WebhookReceiver accepts the envelopes posted by a WebhookSink of another process and delivers them to the sink,
+// e.g. a BusSink, so watchers of the bot see the changes made by the car server.
+// With a secret the X-Signature header must match, a failed delivery responds 503 and the sender retries it.
+type WebhookReceiver struct {
+	secret []byte
+	sink   Sink
+}
+
+func NewWebhookReceiver(secret string, sink Sink) *WebhookReceiver {
+	return &WebhookReceiver{secret: []byte(secret), sink: sink}
+}
+
+func (r *WebhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxEnvelopeSize))
+	if err != nil {
+		http.Error(w, "cannot read the body", http.StatusBadRequest)
+		return
+	}
+	if !r.signed(body, req.Header.Get("X-Signature")) {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+		return
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		http.Error(w, "malformed envelope", http.StatusBadRequest)
+		return
+	}
+	if err := r.sink.Deliver(envelope); err != nil {
+		log.Printf("WebhookReceiver: event %d is not delivered to %s - %v", envelope.ID, r.sink.Name(), err)
+		http.Error(w, "cannot deliver the event", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *WebhookReceiver) signed(body []byte, signature string) bool {
+	if len(r.secret) == 0 {
+		return true
+	}
+
+	return hmac.Equal([]byte(signature), []byte(sign(r.secret, body)))
+}
@@ -0,0 +1,34 @@
+package outbox
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookReceiverTakesSignedEnvelopes(t *testing.T) {
+	received := &testSink{name: "received"}
+	server := httptest.NewServer(NewWebhookReceiver("secret", received))
+	defer server.Close()
+
+	envelope := Envelope{ID: 7, Name: testEvent{}.EventName(), OccurredAt: time.Now(), Payload: []byte(`{"n":1}`)}
+	if err := NewWebhookSink(server.URL, "secret", time.Second).Deliver(envelope); err != nil {
+		t.Fatalf("signed envelope is not accepted - %v", err)
+	}
+	if err := NewWebhookSink(server.URL, "other", time.Second).Deliver(envelope); err == nil {
+		t.Error("envelope signed with another secret is accepted")
+	}
+	if err := NewWebhookSink(server.URL, "", time.Second).Deliver(envelope); err == nil {
+		t.Error("unsigned envelope is accepted")
+	}
+	if want := "[7]"; fmt.Sprint(received.delivered) != want {
+		t.Errorf("sink got %v, want %s", received.delivered, want)
+	}
+
+	// a failed delivery makes the sender retry
+	received.err = fmt.Errorf("bus is closed")
+	if err := NewWebhookSink(server.URL, "secret", time.Second).Deliver(envelope); err == nil {
+		t.Error("envelope the sink failed on is reported as delivered")
+	}
+}
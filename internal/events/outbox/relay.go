@@ -0,0 +1,233 @@
+package outbox
+
+import (
+	"expvar"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+// relayMetrics counts deliveries and failures by sink, "pending" is the size of the outbox at the last pass.
+var relayMetrics = expvar.NewMap("outbox")
+
+// cursorBucket keeps the delivery state of every sink.
+const cursorBucket = "outbox.cursor"
+
+// Sink receives the events, an error makes the relay retry the event later.
+// Events come in the order they were recorded, an event may come again after a failure or a restart.
+type Sink interface {
+	Name() string
+	Deliver(envelope Envelope) error
+}
+
+// cursor is the delivery state of a sink.
+type cursor struct {
+	Delivered uint64    `json:"delivered"`
+	Attempts  int       `json:"attempts,omitempty"`
+	RetryAt   time.Time `json:"retry_at,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+type Config struct {
+	// Poll is how often the outbox is checked for events recorded without Notify, e.g. by another process.
+	Poll time.Duration
+	// Batch is the number of events read in one pass.
+	Batch int
+	// RetryInitial is the delay after the first failure of a sink, it doubles up to RetryMax.
+	RetryInitial time.Duration
+	RetryMax     time.Duration
+}
+
+var DefaultConfig = Config{
+	Poll:         time.Second,
+	Batch:        100,
+	RetryInitial: time.Second,
+	RetryMax:     5 * time.Minute,
+}
+
+// Relay delivers the recorded events to every sink and removes the events delivered to all of them.
+// Sinks progress independently, a failing sink is retried with a backoff and does not hold back the others.
+type Relay struct {
+	outbox *Outbox
+	store  storage.Store
+	sinks  []Sink
+	config Config
+	now    func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func NewRelay(outbox *Outbox, config Config, sinks ...Sink) *Relay {
+	return &Relay{
+		outbox: outbox,
+		store:  outbox.store,
+		sinks:  sinks,
+		config: config,
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (r *Relay) Start() {
+	go r.loop()
+}
+
+// Stop waits for the current pass to finish, undelivered events are left for the next start.
+func (r *Relay) Stop() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+	<-r.done
+}
+
+func (r *Relay) loop() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.Poll)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Deliver(); err != nil {
+			log.Printf("Relay: cannot deliver events - %v", err)
+		}
+
+		select {
+		case <-r.stop:
+			return
+		case <-r.outbox.recorded:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Deliver makes one pass over the outbox, it is called by the relay loop.
+// Every sink reads its batch from its own cursor, so a sink which keeps failing does not hold back the others.
+// The storage is written only when a cursor has moved or there are events delivered to all sinks.
+func (r *Relay) Deliver() error {
+	cursors := make(map[string]cursor, len(r.sinks))
+	batches := make(map[string][]Envelope, len(r.sinks))
+	var keys []string
+	err := r.store.View(func(tx storage.Tx) error {
+		var err error
+		if keys, err = tx.Keys(bucket); err != nil {
+			return err
+		}
+		relayMetrics.Set("pending", intVar(len(keys)))
+
+		loaded := make(map[string]Envelope)
+		for _, sink := range r.sinks {
+			var c cursor
+			if _, err := tx.Get(cursorBucket, sink.Name(), &c); err != nil {
+				return err
+			}
+			cursors[sink.Name()] = c
+
+			// keys are sorted, the sink has not received the ones after its cursor
+			first := sort.SearchStrings(keys, envelopeKey(c.Delivered+1))
+			last := first + r.config.Batch
+			if last > len(keys) {
+				last = len(keys)
+			}
+			for _, key := range keys[first:last] {
+				envelope, found := loaded[key]
+				if !found {
+					if _, err := tx.Get(bucket, key, &envelope); err != nil {
+						return err
+					}
+					loaded[key] = envelope
+				}
+				batches[sink.Name()] = append(batches[sink.Name()], envelope)
+			}
+		}
+
+		return nil
+	})
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+
+	now := r.now()
+	moved := make(map[string]cursor)
+	delivered := ^uint64(0)
+	for _, sink := range r.sinks {
+		c, changed := r.deliverTo(sink, cursors[sink.Name()], batches[sink.Name()], now)
+		if changed {
+			moved[sink.Name()] = c
+		}
+		if c.Delivered < delivered {
+			delivered = c.Delivered
+		}
+	}
+
+	// events up to the one delivered to every sink are not needed anymore
+	done := sort.SearchStrings(keys, envelopeKey(delivered+1))
+	if delivered == ^uint64(0) {
+		done = len(keys)
+	}
+	if len(moved) == 0 && done == 0 {
+		return nil
+	}
+
+	return r.store.Update(func(tx storage.Tx) error {
+		for name, c := range moved {
+			if err := tx.Put(cursorBucket, name, c); err != nil {
+				return err
+			}
+		}
+		for _, key := range keys[:done] {
+			if err := tx.Delete(bucket, key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// deliverTo sends the envelopes to the sink and stops at the first failure, it reports whether the cursor has changed.
+func (r *Relay) deliverTo(sink Sink, c cursor, envelopes []Envelope, now time.Time) (cursor, bool) {
+	if len(envelopes) == 0 || now.Before(c.RetryAt) {
+		return c, false
+	}
+
+	for _, envelope := range envelopes {
+		if err := sink.Deliver(envelope); err != nil {
+			relayMetrics.Add("failed."+sink.Name(), 1)
+			c.Attempts++
+			c.RetryAt = now.Add(r.delay(c.Attempts))
+			c.LastError = err.Error()
+			log.Printf("Relay: sink %s failed on event %d (attempt %d), retrying at %s - %v",
+				sink.Name(), envelope.ID, c.Attempts, c.RetryAt.Format(time.RFC3339), err)
+
+			return c, true
+		}
+
+		relayMetrics.Add("delivered."+sink.Name(), 1)
+		c = cursor{Delivered: envelope.ID}
+	}
+
+	return c, true
+}
+
+// delay doubles the initial delay with every attempt of the sink.
+func (r *Relay) delay(attempts int) time.Duration {
+	d := r.config.RetryInitial << uint(attempts-1)
+	if d > r.config.RetryMax || d <= 0 {
+		d = r.config.RetryMax
+	}
+
+	return d
+}
+
+func intVar(value int) *expvar.Int {
+	v := new(expvar.Int)
+	v.Set(int64(value))
+
+	return v
+}
@@ -0,0 +1,101 @@
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+type testEvent struct {
+	N int `json:"n"`
+}
+
+func (testEvent) EventName() string {
+	return "test.event"
+}
+
+type testSink struct {
+	name      string
+	err       error
+	delivered []uint64
+}
+
+func (s *testSink) Name() string {
+	return s.name
+}
+
+func (s *testSink) Deliver(envelope Envelope) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.delivered = append(s.delivered, envelope.ID)
+	return nil
+}
+
+// countingStore counts the transactions which write.
+type countingStore struct {
+	storage.Store
+	updates int
+}
+
+func (s *countingStore) Update(fn func(tx storage.Tx) error) error {
+	s.updates++
+	return s.Store.Update(fn)
+}
+
+func TestRelayFailingSinkDoesNotHoldBackOthers(t *testing.T) {
+	store := &countingStore{Store: storage.NewMemoryStore()}
+	outbox := New(store)
+	for n := 0; n < 5; n++ {
+		if err := outbox.Publish(testEvent{N: n}); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	good := &testSink{name: "good"}
+	bad := &testSink{name: "bad", err: errors.New("down")}
+	relay := NewRelay(outbox, Config{Batch: 2, RetryInitial: DefaultConfig.RetryInitial, RetryMax: DefaultConfig.RetryMax}, good, bad)
+
+	for pass := 0; pass < 3; pass++ {
+		if err := relay.Deliver(); err != nil {
+			t.Fatalf("Deliver: %v", err)
+		}
+	}
+	if want := "[1 2 3 4 5]"; fmt.Sprint(good.delivered) != want {
+		t.Errorf("good sink got %s, want %s", fmt.Sprint(good.delivered), want)
+	}
+
+	// the good sink is done and the bad one waits for its retry, nothing is written
+	store.updates = 0
+	if err := relay.Deliver(); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if store.updates != 0 {
+		t.Errorf("idle pass wrote the storage %d times", store.updates)
+	}
+
+	// the events are kept until the bad sink gets them
+	bad.err = nil
+	relay.now = func() time.Time { return time.Now().Add(DefaultConfig.RetryMax) }
+	for pass := 0; pass < 3; pass++ {
+		if err := relay.Deliver(); err != nil {
+			t.Fatalf("Deliver: %v", err)
+		}
+	}
+	if want := "[1 2 3 4 5]"; fmt.Sprint(bad.delivered) != want {
+		t.Errorf("bad sink got %s after recovering, want %s", fmt.Sprint(bad.delivered), want)
+	}
+	err := store.View(func(tx storage.Tx) error {
+		keys, err := tx.Keys(bucket)
+		if len(keys) != 0 {
+			t.Errorf("outbox keeps %d events delivered to all sinks", len(keys))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("View: %v", err)
+	}
+}
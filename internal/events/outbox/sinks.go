@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/events"
+)
+
+// BusSink hands the events to the in-process subscribers, e.g. watch notifications.
+type BusSink struct {
+	publisher events.Publisher
+}
+
+func NewBusSink(publisher events.Publisher) *BusSink {
+	return &BusSink{publisher: publisher}
+}
+
+func (s *BusSink) Name() string {
+	return "bus"
+}
+
+func (s *BusSink) Deliver(envelope Envelope) error {
+	event, err := envelope.Event()
+	if err != nil {
+		// retrying does not help an event nobody can decode
+		log.Printf("BusSink: event %d is skipped - %v", envelope.ID, err)
+		return nil
+	}
+
+	return s.publisher.Publish(event)
+}
+
+// LogSink writes the events to the log of the bot.
+type LogSink struct{}
+
+func (LogSink) Name() string {
+	return "log"
+}
+
+func (LogSink) Deliver(envelope Envelope) error {
+	log.Printf("event %d %s %s", envelope.ID, envelope.Name, envelope.Payload)
+	return nil
+}
+
+// FileSink appends the envelopes to a file as JSON lines.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Deliver syncs the file, so a delivered event survives a crash.
+func (s *FileSink) Deliver(envelope Envelope) error {
+	line, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// WebhookSink posts every envelope as JSON to the URL, any status but 2xx is a failure.
+// The X-Event-ID header lets the receiver drop the events it has already seen.
+// With a secret the body is signed with HMAC-SHA256 in the X-Signature header as "sha256=<hex>".
+type WebhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func NewWebhookSink(url, secret string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Deliver(envelope Envelope) error {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatUint(envelope.ID, 10))
+	req.Header.Set("X-Event-Name", envelope.Name)
+	if len(s.secret) > 0 {
+		req.Header.Set("X-Signature", sign(s.secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+
+	return nil
+}
+
+// sign returns the X-Signature header of the body.
+func sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Producer is the part of a message broker client the BrokerSink needs,
+// e.g. a thin wrapper of a Kafka or NATS producer. Produce returns once the broker has acknowledged the message.
+type Producer interface {
+	Produce(topic string, key, value []byte) error
+}
+
+// BrokerSink publishes the envelopes to the topic, keyed by the event name so events of a kind stay ordered.
+type BrokerSink struct {
+	producer Producer
+	topic    string
+}
+
+func NewBrokerSink(producer Producer, topic string) *BrokerSink {
+	return &BrokerSink{producer: producer, topic: topic}
+}
+
+func (s *BrokerSink) Name() string {
+	return "broker"
+}
+
+func (s *BrokerSink) Deliver(envelope Envelope) error {
+	value, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	return s.producer.Produce(s.topic, []byte(envelope.Name), value)
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	typesMu sync.RWMutex
+	types   = map[string]reflect.Type{}
+)
+
+// Register records the type of the event, so events read back by name, e.g. from the outbox, keep their types.
+// Like gob.Register it is meant to be called from init functions of the packages defining events.
+func Register(event Event) {
+	typesMu.Lock()
+	defer typesMu.Unlock()
+
+	types[event.EventName()] = reflect.TypeOf(event)
+}
+
+// Decode builds the registered event with the name from its JSON encoding.
+func Decode(name string, payload []byte) (Event, error) {
+	typesMu.RLock()
+	eventType, found := types[name]
+	typesMu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("event %q is not registered", name)
+	}
+
+	value := reflect.New(eventType)
+	if err := json.Unmarshal(payload, value.Interface()); err != nil {
+		return nil, fmt.Errorf("cannot decode event %q: %w", name, err)
+	}
+
+	return value.Elem().Interface().(Event), nil
+}
@@ -1,7 +1,15 @@
 package insurance
 
 type Car struct {
-	Title string
+	ID    uint64 `json:"id"`
+	Title string `json:"title"`
+	Make  string `json:"make,omitempty"`
+	// Year is the model year, zero when unknown.
+	Year int `json:"year,omitempty"`
+	// Quote is the last premium quote saved for the car.
+	Quote *Quote `json:"quote,omitempty"`
+	// Version grows with every update, an update is accepted only over the current version.
+	Version uint64 `json:"version"`
 }
 
 func (c Car) String() string {
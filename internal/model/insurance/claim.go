@@ -0,0 +1,75 @@
+package insurance
+
+import (
+	"fmt"
+	"time"
+)
+
+type ClaimStatus string
+
+const (
+	ClaimFiled       ClaimStatus = "filed"
+	ClaimUnderReview ClaimStatus = "under_review"
+	ClaimApproved    ClaimStatus = "approved"
+	ClaimRejected    ClaimStatus = "rejected"
+	ClaimPaid        ClaimStatus = "paid"
+)
+
+// claimTransitions lists the statuses each status may be changed to, rejected and paid claims are final.
+var claimTransitions = map[ClaimStatus][]ClaimStatus{
+	ClaimFiled:       {ClaimUnderReview},
+	ClaimUnderReview: {ClaimApproved, ClaimRejected},
+	ClaimApproved:    {ClaimPaid},
+}
+
+// Next returns the statuses the claim may be moved to from s.
+func (s ClaimStatus) Next() []ClaimStatus {
+	return append([]ClaimStatus(nil), claimTransitions[s]...)
+}
+
+// CanBecome reports whether the transition from s to next is allowed.
+func (s ClaimStatus) CanBecome(next ClaimStatus) bool {
+	for _, allowed := range claimTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TransitionError is returned for a status change the workflow does not allow.
+type TransitionError struct {
+	From, To ClaimStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("claim cannot go from %s to %s", e.From, e.To)
+}
+
+// Claim is an incident with an insured car reported by a user.
+type Claim struct {
+	ID          uint64 `json:"id"`
+	CarID       uint64 `json:"car_id"`
+	Description string `json:"description"`
+	Amount      Money  `json:"amount"`
+	// Photos are Telegram file IDs.
+	Photos  []string    `json:"photos,omitempty"`
+	Status  ClaimStatus `json:"status"`
+	FiledBy int64       `json:"filed_by"`
+	FiledAt time.Time   `json:"filed_at"`
+}
+
+// Transition moves the claim to the next status if the workflow allows it.
+func (c *Claim) Transition(next ClaimStatus) error {
+	if !c.Status.CanBecome(next) {
+		return &TransitionError{From: c.Status, To: next}
+	}
+	c.Status = next
+
+	return nil
+}
+
+func (c Claim) String() string {
+	return fmt.Sprintf("claim for car %d: %s (%s)", c.CarID, c.Amount, c.Status)
+}
@@ -0,0 +1,70 @@
+package insurance
+
+import (
+	"fmt"
+	"time"
+)
+
+type Coverage string
+
+const (
+	CoverageLiability     Coverage = "liability"
+	CoverageCollision     Coverage = "collision"
+	CoverageComprehensive Coverage = "comprehensive"
+)
+
+type PolicyStatus string
+
+const (
+	PolicyDraft     PolicyStatus = "draft"
+	PolicyActive    PolicyStatus = "active"
+	PolicyExpired   PolicyStatus = "expired"
+	PolicyCancelled PolicyStatus = "cancelled"
+)
+
+// Money is an amount in minor units, e.g. kopecks.
+type Money int64
+
+// MoneyFromFloat rounds the amount in major units to minor ones.
+func MoneyFromFloat(amount float64) Money {
+	if amount < 0 {
+		return -MoneyFromFloat(-amount)
+	}
+
+	return Money(amount*100 + 0.5)
+}
+
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign, m = "-", -m
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, m/100, m%100)
+}
+
+// Policy insures one or more cars for the period from Start to End inclusive.
+type Policy struct {
+	ID       uint64       `json:"id"`
+	CarIDs   []uint64     `json:"car_ids"`
+	Coverage Coverage     `json:"coverage"`
+	Start    time.Time    `json:"start"`
+	End      time.Time    `json:"end"`
+	Premium  Money        `json:"premium"`
+	Status   PolicyStatus `json:"status"`
+}
+
+// Covers reports whether the policy insures the car.
+func (p Policy) Covers(carID uint64) bool {
+	for _, id := range p.CarIDs {
+		if id == carID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p Policy) String() string {
+	return fmt.Sprintf("%s %s–%s", p.Coverage, p.Start.Format("2006-01-02"), p.End.Format("2006-01-02"))
+}
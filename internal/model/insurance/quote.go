@@ -0,0 +1,25 @@
+package insurance
+
+import "time"
+
+// QuoteItem is a step of the premium calculation: the base rate or an adjustment by a factor.
+type QuoteItem struct {
+	// Name is the rated attribute, e.g. "base" or "driver_age".
+	Name string `json:"name"`
+	// Detail is the value of the attribute the factor was chosen for.
+	Detail string  `json:"detail"`
+	Factor float64 `json:"factor"`
+	// Amount is the change of the premium made by the step.
+	Amount Money `json:"amount"`
+}
+
+// Quote is an itemised premium estimate for a car, Premium is the sum of the item amounts.
+type Quote struct {
+	Coverage   Coverage    `json:"coverage"`
+	Deductible Money       `json:"deductible"`
+	DriverAge  int         `json:"driver_age"`
+	Region     string      `json:"region"`
+	Items      []QuoteItem `json:"items"`
+	Premium    Money       `json:"premium"`
+	QuotedAt   time.Time   `json:"quoted_at"`
+}
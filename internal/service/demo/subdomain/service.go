@@ -1,5 +1,7 @@
 package subdomain
 
+import "fmt"
+
 type Service struct{}
 
 func NewService() *Service {
@@ -11,5 +13,9 @@ func (s *Service) List() []Subdomain {
 }
 
 func (s *Service) Get(idx int) (*Subdomain, error) {
+	if idx < 0 || idx >= len(allEntities) {
+		return nil, fmt.Errorf("index %d is out of range", idx)
+	}
+
 	return &allEntities[idx], nil
 }
@@ -0,0 +1,122 @@
+package car
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/cache"
+	"github.com/ozonmp/omp-bot/internal/events"
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+type CacheConfig struct {
+	// TTL bounds how long changes made past the decorator, e.g. by other clients of the car server, stay unseen.
+	TTL time.Duration
+	// Size is the number of cars and, separately, of list pages kept.
+	Size int
+}
+
+var DefaultCacheConfig = CacheConfig{
+	TTL:  30 * time.Second,
+	Size: 1000,
+}
+
+// CachingCarService is a read-through cache of the wrapped service. Cars and list pages are cached separately,
+// Update and Remove invalidate the car, and every mutation invalidates all pages as it may shift them.
+// Hits and misses are reported in the "cache" expvar map under "car" and "car_list".
+type CachingCarService struct {
+	CarService
+	cars  *cache.LRU
+	pages *cache.LRU
+}
+
+func NewCachingCarService(next CarService, config CacheConfig) *CachingCarService {
+	return &CachingCarService{
+		CarService: next,
+		cars:       cache.New("car", config.Size, config.TTL),
+		pages:      cache.New("car_list", config.Size, config.TTL),
+	}
+}
+
+func (s *CachingCarService) Describe(carID uint64) (*insurance.Car, error) {
+	key := carKey(carID)
+	if cached, found := s.cars.Get(key); found {
+		car := clone(cached.(insurance.Car))
+		return &car, nil
+	}
+
+	generation := s.cars.Generation()
+	car, err := s.CarService.Describe(carID)
+	if err != nil {
+		return nil, err
+	}
+	s.cars.Add(key, clone(*car), generation)
+
+	return car, nil
+}
+
+func (s *CachingCarService) List(cursor uint64, limit uint64) ([]insurance.Car, error) {
+	return s.ListOrdered(OrderByID, cursor, limit)
+}
+
+func (s *CachingCarService) ListOrdered(order Order, cursor uint64, limit uint64) ([]insurance.Car, error) {
+	key := fmt.Sprintf("%s/%d/%d", order, cursor, limit)
+	if cached, found := s.pages.Get(key); found {
+		return cloneAll(cached.([]insurance.Car)), nil
+	}
+
+	generation := s.pages.Generation()
+	cars, err := s.CarService.ListOrdered(order, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	s.pages.Add(key, cloneAll(cars), generation)
+
+	return cars, nil
+}
+
+func (s *CachingCarService) Create(car insurance.Car) (uint64, error) {
+	defer s.pages.Purge()
+
+	return s.CarService.Create(car)
+}
+
+// Update invalidates even after a failure, the change may have been made, e.g. when a remote call timed out.
+func (s *CachingCarService) Update(carID uint64, car insurance.Car) error {
+	defer s.invalidate(carID)
+
+	return s.CarService.Update(carID, car)
+}
+
+func (s *CachingCarService) Remove(carID uint64) (bool, error) {
+	defer s.invalidate(carID)
+
+	return s.CarService.Remove(carID)
+}
+
+// Handle invalidates the cache on the events of changes made past the decorator, e.g. by other clients
+// of the car server. Subscribe it to the bus receiving the events of the server.
+func (s *CachingCarService) Handle(event events.Event) {
+	switch e := event.(type) {
+	case CarCreated:
+		s.pages.Purge()
+	case CarUpdated:
+		s.invalidate(e.After.ID)
+	case CarRemoved:
+		s.invalidate(e.Car.ID)
+	}
+}
+
+func (s *CachingCarService) invalidate(carID uint64) {
+	s.cars.Remove(carKey(carID))
+	s.pages.Purge()
+}
+
+func cloneAll(cars []insurance.Car) []insurance.Car {
+	copied := make([]insurance.Car, len(cars))
+	for i, car := range cars {
+		copied[i] = clone(car)
+	}
+
+	return copied
+}
@@ -0,0 +1,141 @@
+package car
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+// countingCarService counts the reads reaching the wrapped service.
+type countingCarService struct {
+	CarService
+	describes, lists int
+}
+
+func (s *countingCarService) Describe(carID uint64) (*insurance.Car, error) {
+	s.describes++
+	return s.CarService.Describe(carID)
+}
+
+func (s *countingCarService) ListOrdered(order Order, cursor uint64, limit uint64) ([]insurance.Car, error) {
+	s.lists++
+	return s.CarService.ListOrdered(order, cursor, limit)
+}
+
+func newTestCache() (*CachingCarService, *countingCarService) {
+	next := &countingCarService{CarService: NewDummyCarService()}
+
+	return NewCachingCarService(next, CacheConfig{TTL: time.Hour, Size: 10}), next
+}
+
+func TestCachingCarServiceReadsThrough(t *testing.T) {
+	s, next := newTestCache()
+
+	for i := 0; i < 3; i++ {
+		car, err := s.Describe(2)
+		if err != nil || car.Title != "Nissan" {
+			t.Fatalf("Describe(2) = %+v, %v", car, err)
+		}
+		car.Title = "changed by a caller"
+
+		cars, err := s.List(0, 2)
+		if err != nil || len(cars) != 2 {
+			t.Fatalf("List(0, 2) = %+v, %v", cars, err)
+		}
+		cars[0].Title = "changed by a caller"
+	}
+	if next.describes != 1 || next.lists != 1 {
+		t.Errorf("service is called %d and %d times, want each read once", next.describes, next.lists)
+	}
+
+	if _, err := s.Describe(100); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Describe(100) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCachingCarServiceInvalidatesOnChanges(t *testing.T) {
+	s, next := newTestCache()
+
+	car, _ := s.Describe(2)
+	_, _ = s.List(0, 20)
+
+	car.Title = "Nissan Leaf"
+	if err := s.Update(2, *car); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, _ := s.Describe(2); got.Title != "Nissan Leaf" {
+		t.Errorf("Describe after Update returned %q", got.Title)
+	}
+	if cars, _ := s.List(0, 20); cars[1].Title != "Nissan Leaf" {
+		t.Errorf("List after Update returned %q", cars[1].Title)
+	}
+
+	// a failed update invalidates too, a remote one may have been made
+	before := next.describes
+	if err := s.Update(2, *car); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Update over an old version returned %v, want ErrConflict", err)
+	}
+	_, _ = s.Describe(2)
+	if next.describes != before+1 {
+		t.Error("car is not read again after a failed update")
+	}
+
+	if _, err := s.Create(insurance.Car{Title: "Kia"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if cars, _ := s.List(0, 20); cars[len(cars)-1].Title != "Kia" {
+		t.Error("List after Create does not show the new car")
+	}
+
+	if _, err := s.Remove(2); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := s.Describe(2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Describe after Remove returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestCachingCarServiceHandlesEvents(t *testing.T) {
+	s, next := newTestCache()
+
+	// the changes are made past the decorator, like other clients of the car server do
+	car, _ := s.Describe(2)
+	_, _ = s.List(0, 20)
+	before := *car
+	car.Title = "Nissan Leaf"
+	if err := next.Update(2, *car); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, _ := s.Describe(2); got.Title != "Nissan" {
+		t.Fatalf("cache is bypassed, Describe returned %q", got.Title)
+	}
+
+	after, _ := next.Describe(2)
+	s.Handle(CarUpdated{Before: before, After: *after})
+	if got, _ := s.Describe(2); got.Title != "Nissan Leaf" {
+		t.Errorf("Describe after CarUpdated returned %q", got.Title)
+	}
+	if cars, _ := s.List(0, 20); cars[1].Title != "Nissan Leaf" {
+		t.Errorf("List after CarUpdated returned %q", cars[1].Title)
+	}
+
+	id, _ := next.Create(insurance.Car{Title: "Kia"})
+	created, _ := next.Describe(id)
+	s.Handle(CarCreated{Car: *created})
+	if cars, _ := s.List(0, 20); cars[len(cars)-1].Title != "Kia" {
+		t.Error("List after CarCreated does not show the new car")
+	}
+
+	if _, err := next.Remove(2); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	s.Handle(CarRemoved{Car: *after})
+	if _, err := s.Describe(2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Describe after CarRemoved returned %v, want ErrNotFound", err)
+	}
+	if cars, _ := s.List(0, 20); cars[1].ID == 2 {
+		t.Error("List after CarRemoved shows the removed car")
+	}
+}
@@ -0,0 +1,37 @@
+package car
+
+import (
+	"github.com/ozonmp/omp-bot/internal/events"
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+func init() {
+	events.Register(CarCreated{})
+	events.Register(CarUpdated{})
+	events.Register(CarRemoved{})
+}
+
+type CarCreated struct {
+	Car insurance.Car `json:"car"`
+}
+
+func (CarCreated) EventName() string {
+	return "insurance.car.created"
+}
+
+type CarUpdated struct {
+	Before insurance.Car `json:"before"`
+	After  insurance.Car `json:"after"`
+}
+
+func (CarUpdated) EventName() string {
+	return "insurance.car.updated"
+}
+
+type CarRemoved struct {
+	Car insurance.Car `json:"car"`
+}
+
+func (CarRemoved) EventName() string {
+	return "insurance.car.removed"
+}
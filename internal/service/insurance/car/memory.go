@@ -0,0 +1,156 @@
+package car
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+// MemoryCarService is the reference CarService implementation keeping cars in memory.
+// IDs start at 1 and are never reused, so removing a car does not change the IDs of the others.
+type MemoryCarService struct {
+	mu     sync.RWMutex
+	cars   map[uint64]insurance.Car
+	ids    []uint64
+	lastID uint64
+}
+
+func NewMemoryCarService(cars ...insurance.Car) *MemoryCarService {
+	s := &MemoryCarService{
+		cars: make(map[uint64]insurance.Car, len(cars)),
+	}
+	for _, car := range cars {
+		s.create(car)
+	}
+
+	return s
+}
+
+// NewDummyCarService returns a service filled with sample cars.
+func NewDummyCarService() *MemoryCarService {
+	return NewMemoryCarService(SampleCars()...)
+}
+
+// SampleCars are the cars the bot shows without a data directory.
+func SampleCars() []insurance.Car {
+	return []insurance.Car{
+		{Title: "Toyota"},
+		{Title: "Nissan"},
+		{Title: "Infinity"},
+		{Title: "Mazda"},
+		{Title: "Honda"},
+		{Title: "Lexus"},
+		{Title: "Acura"},
+		{Title: "Suzuki"},
+		{Title: "Isuzu"},
+		{Title: "Mitsubishi"},
+		{Title: "Subaru"},
+	}
+}
+
+func (s *MemoryCarService) Describe(carID uint64) (*insurance.Car, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	car, found := s.cars[carID]
+	if !found {
+		return nil, fmt.Errorf("no car with id %d: %w", carID, ErrNotFound)
+	}
+	car = clone(car)
+
+	return &car, nil
+}
+
+func (s *MemoryCarService) List(cursor uint64, limit uint64) ([]insurance.Car, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if cursor >= uint64(len(s.ids)) {
+		return []insurance.Car{}, nil
+	}
+	high := uint64(len(s.ids))
+	if limit < high-cursor {
+		high = cursor + limit
+	}
+
+	cars := make([]insurance.Car, 0, high-cursor)
+	for _, id := range s.ids[cursor:high] {
+		cars = append(cars, clone(s.cars[id]))
+	}
+
+	return cars, nil
+}
+
+func (s *MemoryCarService) ListOrdered(order Order, cursor uint64, limit uint64) ([]insurance.Car, error) {
+	if order == OrderByID {
+		return s.List(cursor, limit)
+	}
+
+	s.mu.RLock()
+	cars := make([]insurance.Car, 0, len(s.ids))
+	for _, id := range s.ids {
+		cars = append(cars, clone(s.cars[id]))
+	}
+	s.mu.RUnlock()
+
+	sortCars(cars, order)
+
+	return page(cars, cursor, limit), nil
+}
+
+func (s *MemoryCarService) Create(car insurance.Car) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.create(car), nil
+}
+
+func (s *MemoryCarService) create(car insurance.Car) uint64 {
+	s.lastID++
+	car.ID, car.Version = s.lastID, 1
+	s.cars[car.ID] = clone(car)
+	s.ids = append(s.ids, car.ID)
+
+	return car.ID
+}
+
+func (s *MemoryCarService) Update(carID uint64, car insurance.Car) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, found := s.cars[carID]
+	if !found {
+		return fmt.Errorf("no car with id %d: %w", carID, ErrNotFound)
+	}
+	if err := checkVersion(stored, car); err != nil {
+		return err
+	}
+	car.ID = carID
+	car.Version++
+	s.cars[carID] = clone(car)
+
+	return nil
+}
+
+func (s *MemoryCarService) Remove(carID uint64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.cars[carID]; !found {
+		return false, fmt.Errorf("no car with id %d: %w", carID, ErrNotFound)
+	}
+	delete(s.cars, carID)
+
+	// ids are sorted as they are issued in increasing order
+	i := sort.Search(len(s.ids), func(i int) bool { return s.ids[i] >= carID })
+	s.ids = append(s.ids[:i], s.ids[i+1:]...)
+
+	return true, nil
+}
+
+// Ping always succeeds as the storage is kept in memory.
+func (s *MemoryCarService) Ping() error {
+	return nil
+}
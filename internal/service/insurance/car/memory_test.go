@@ -0,0 +1,217 @@
+package car
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+func quotedCar(title string) insurance.Car {
+	return insurance.Car{
+		Title: title,
+		Quote: &insurance.Quote{
+			Coverage: insurance.CoverageComprehensive,
+			Items:    []insurance.QuoteItem{{Name: "base", Amount: 1000}},
+			Premium:  1000,
+		},
+	}
+}
+
+// TestMemoryCarServiceConcurrentUse is meant to be run with -race.
+func TestMemoryCarServiceConcurrentUse(t *testing.T) {
+	const (
+		writers   = 8
+		readers   = 8
+		perWriter = 50
+	)
+
+	s := NewMemoryCarService()
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created = make(map[uint64]string)
+		removed = make(map[uint64]bool)
+		stop    = make(chan struct{})
+	)
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < perWriter; i++ {
+				title := fmt.Sprintf("car %d-%d", w, i)
+				id, err := s.Create(quotedCar(title))
+				if err != nil {
+					t.Errorf("Create: %v", err)
+					return
+				}
+				mu.Lock()
+				if _, found := created[id]; found {
+					t.Errorf("Create returned id %d twice", id)
+				}
+				created[id] = title
+				mu.Unlock()
+
+				car, err := s.Describe(id)
+				if err != nil {
+					t.Errorf("Describe(%d): %v", id, err)
+					return
+				}
+				car.Make = "Make"
+				if err := s.Update(id, *car); err != nil {
+					t.Errorf("Update(%d): %v", id, err)
+				}
+
+				if i%3 == 0 {
+					if _, err := s.Remove(id); err != nil {
+						t.Errorf("Remove(%d): %v", id, err)
+					}
+					mu.Lock()
+					removed[id] = true
+					mu.Unlock()
+				}
+			}
+		}(w)
+	}
+
+	var readersWG sync.WaitGroup
+	for r := 0; r < readers; r++ {
+		readersWG.Add(1)
+		go func(r int) {
+			defer readersWG.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				cars, err := s.List(0, 20)
+				if err != nil {
+					t.Errorf("List: %v", err)
+					return
+				}
+				for i := 1; i < len(cars); i++ {
+					if cars[i-1].ID >= cars[i].ID {
+						t.Errorf("List is not ordered by ID: %d before %d", cars[i-1].ID, cars[i].ID)
+					}
+				}
+				for _, car := range cars {
+					// values handed out must not be shared with the other readers
+					car.Quote.Items[0].Amount++
+				}
+
+				if _, err := s.ListOrdered(Orders[r%len(Orders)], 0, 20); err != nil {
+					t.Errorf("ListOrdered: %v", err)
+					return
+				}
+				if len(cars) > 0 {
+					_, err := s.Describe(cars[0].ID)
+					if err != nil && !errors.Is(err, ErrNotFound) {
+						t.Errorf("Describe: %v", err)
+					}
+				}
+			}
+		}(r)
+	}
+
+	wg.Wait()
+	close(stop)
+	readersWG.Wait()
+
+	cars, err := s.List(0, writers*perWriter)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if want := len(created) - len(removed); len(cars) != want {
+		t.Fatalf("List returned %d cars, want %d", len(cars), want)
+	}
+	for _, car := range cars {
+		if removed[car.ID] {
+			t.Errorf("removed car %d is listed", car.ID)
+		}
+		if car.Title != created[car.ID] {
+			t.Errorf("car %d has title %q, want %q", car.ID, car.Title, created[car.ID])
+		}
+		if car.Make != "Make" || car.Version != 2 {
+			t.Errorf("car %d has make %q and version %d, want the update applied once", car.ID, car.Make, car.Version)
+		}
+		if amount := car.Quote.Items[0].Amount; amount != 1000 {
+			t.Errorf("car %d has quote item amount %d changed by a reader", car.ID, amount)
+		}
+	}
+}
+
+func TestMemoryCarServiceKeepsIDs(t *testing.T) {
+	s := NewMemoryCarService(insurance.Car{Title: "Toyota"}, insurance.Car{Title: "Nissan"}, insurance.Car{Title: "Mazda"})
+
+	if _, err := s.Remove(2); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	id, err := s.Create(insurance.Car{Title: "Honda"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if id != 4 {
+		t.Errorf("Create returned id %d, want 4 as ids are never reused", id)
+	}
+
+	cars, err := s.List(0, 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var got []string
+	for _, car := range cars {
+		got = append(got, fmt.Sprintf("%d:%s", car.ID, car.Title))
+	}
+	if want := "[1:Toyota 3:Mazda 4:Honda]"; fmt.Sprint(got) != want {
+		t.Errorf("List returned %v, want %s", got, want)
+	}
+
+	if _, err := s.Describe(2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Describe of a removed car returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryCarServiceReturnsCopies(t *testing.T) {
+	car := quotedCar("Toyota")
+	s := NewMemoryCarService()
+	id, err := s.Create(car)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	car.Quote.Items[0].Amount = 1
+
+	described, err := s.Describe(id)
+	if err != nil {
+		t.Fatalf("Describe: %v", err)
+	}
+	described.Title = "changed"
+	described.Quote.Items[0].Amount = 2
+	described.Quote.Items = append(described.Quote.Items, insurance.QuoteItem{Name: "extra"})
+
+	listed, err := s.List(0, 1)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	listed[0].Quote.Items[0].Amount = 3
+
+	ordered, err := s.ListOrdered(OrderByTitle, 0, 1)
+	if err != nil {
+		t.Fatalf("ListOrdered: %v", err)
+	}
+	ordered[0].Quote.Items[0].Amount = 4
+
+	stored, err := s.Describe(id)
+	if err != nil {
+		t.Fatalf("Describe: %v", err)
+	}
+	if stored.Title != "Toyota" || len(stored.Quote.Items) != 1 || stored.Quote.Items[0].Amount != 1000 {
+		t.Errorf("stored car is changed through a returned value: %+v %+v", *stored, stored.Quote.Items)
+	}
+}
@@ -0,0 +1,68 @@
+package car
+
+import (
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	carpb "github.com/ozonmp/omp-bot/pkg/insurance/car/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func carToProto(car insurance.Car) *carpb.Car {
+	message := &carpb.Car{
+		Id:      car.ID,
+		Title:   car.Title,
+		Make:    car.Make,
+		Year:    int32(car.Year),
+		Version: car.Version,
+	}
+	if car.Quote != nil {
+		message.Quote = &carpb.Quote{
+			Coverage:   string(car.Quote.Coverage),
+			Deductible: int64(car.Quote.Deductible),
+			DriverAge:  int32(car.Quote.DriverAge),
+			Region:     car.Quote.Region,
+			Premium:    int64(car.Quote.Premium),
+			QuotedAt:   timestamppb.New(car.Quote.QuotedAt),
+		}
+		for _, item := range car.Quote.Items {
+			message.Quote.Items = append(message.Quote.Items, &carpb.QuoteItem{
+				Name:   item.Name,
+				Detail: item.Detail,
+				Factor: item.Factor,
+				Amount: int64(item.Amount),
+			})
+		}
+	}
+
+	return message
+}
+
+// carFromProto treats a missing message as an empty car.
+func carFromProto(message *carpb.Car) insurance.Car {
+	car := insurance.Car{
+		ID:      message.GetId(),
+		Title:   message.GetTitle(),
+		Make:    message.GetMake(),
+		Year:    int(message.GetYear()),
+		Version: message.GetVersion(),
+	}
+	if quote := message.GetQuote(); quote != nil {
+		car.Quote = &insurance.Quote{
+			Coverage:   insurance.Coverage(quote.Coverage),
+			Deductible: insurance.Money(quote.Deductible),
+			DriverAge:  int(quote.DriverAge),
+			Region:     quote.Region,
+			Premium:    insurance.Money(quote.Premium),
+			QuotedAt:   quote.QuotedAt.AsTime(),
+		}
+		for _, item := range quote.Items {
+			car.Quote.Items = append(car.Quote.Items, insurance.QuoteItem{
+				Name:   item.Name,
+				Detail: item.Detail,
+				Factor: item.Factor,
+				Amount: insurance.Money(item.Amount),
+			})
+		}
+	}
+
+	return car
+}
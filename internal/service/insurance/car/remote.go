@@ -0,0 +1,194 @@
+package car
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	carpb "github.com/ozonmp/omp-bot/pkg/insurance/car/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// HealthService is the name the car server reports its health under.
+const HealthService = "ozonmp.insurance.car.v1.CarService"
+
+type RemoteConfig struct {
+	// Timeout is the deadline of one attempt.
+	Timeout time.Duration
+	// Attempts is how many times reads and updates are tried, creating and removing cars is tried once
+	// as a retry could repeat a change which has been made. Updates are safe to retry as they are conditional
+	// on the version, a repeated one fails with ErrConflict at worst.
+	Attempts int
+	// Backoff is the delay before the second attempt, it doubles with every next one.
+	Backoff time.Duration
+}
+
+var DefaultRemoteConfig = RemoteConfig{
+	Timeout:  2 * time.Second,
+	Attempts: 3,
+	Backoff:  100 * time.Millisecond,
+}
+
+// RemoteCarService is the client of the car server, see Server.
+// Unknown cars are reported with ErrNotFound, an unreachable or overloaded server with ErrUnavailable.
+type RemoteCarService struct {
+	conn   *grpc.ClientConn
+	client carpb.CarServiceClient
+	health healthpb.HealthClient
+	config RemoteConfig
+}
+
+// DialRemoteCarService connects lazily, so the bot starts even if the server is down.
+func DialRemoteCarService(addr string, config RemoteConfig) (*RemoteCarService, error) {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RemoteCarService{
+		conn:   conn,
+		client: carpb.NewCarServiceClient(conn),
+		health: healthpb.NewHealthClient(conn),
+		config: config,
+	}, nil
+}
+
+func (s *RemoteCarService) Describe(carID uint64) (*insurance.Car, error) {
+	var resp *carpb.DescribeCarResponse
+	err := s.call(true, func(ctx context.Context) (err error) {
+		resp, err = s.client.DescribeCar(ctx, &carpb.DescribeCarRequest{Id: carID})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	car := carFromProto(resp.GetCar())
+	return &car, nil
+}
+
+func (s *RemoteCarService) List(cursor uint64, limit uint64) ([]insurance.Car, error) {
+	return s.ListOrdered(OrderByID, cursor, limit)
+}
+
+func (s *RemoteCarService) ListOrdered(order Order, cursor uint64, limit uint64) ([]insurance.Car, error) {
+	cars := []insurance.Car{}
+	if limit == 0 {
+		return cars, nil
+	}
+
+	var resp *carpb.ListCarsResponse
+	err := s.call(true, func(ctx context.Context) (err error) {
+		resp, err = s.client.ListCars(ctx, &carpb.ListCarsRequest{Order: string(order), Cursor: cursor, Limit: limit})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, car := range resp.GetCars() {
+		cars = append(cars, carFromProto(car))
+	}
+
+	return cars, nil
+}
+
+func (s *RemoteCarService) Create(car insurance.Car) (uint64, error) {
+	var resp *carpb.CreateCarResponse
+	err := s.call(false, func(ctx context.Context) (err error) {
+		resp, err = s.client.CreateCar(ctx, &carpb.CreateCarRequest{Car: carToProto(car)})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.GetId(), nil
+}
+
+func (s *RemoteCarService) Update(carID uint64, car insurance.Car) error {
+	return s.call(true, func(ctx context.Context) error {
+		_, err := s.client.UpdateCar(ctx, &carpb.UpdateCarRequest{Id: carID, Car: carToProto(car)})
+		return err
+	})
+}
+
+func (s *RemoteCarService) Remove(carID uint64) (bool, error) {
+	err := s.call(false, func(ctx context.Context) error {
+		_, err := s.client.RemoveCar(ctx, &carpb.RemoveCarRequest{Id: carID})
+		return err
+	})
+
+	return err == nil, err
+}
+
+// Ping asks the standard health service of the server.
+func (s *RemoteCarService) Ping() error {
+	return s.call(false, func(ctx context.Context) error {
+		resp, err := s.health.Check(ctx, &healthpb.HealthCheckRequest{Service: HealthService})
+		if err == nil && resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			err = status.Errorf(codes.Unavailable, "car server is %s", resp.GetStatus())
+		}
+		return err
+	})
+}
+
+func (s *RemoteCarService) Close() error {
+	return s.conn.Close()
+}
+
+// call runs the request with the deadline, idempotent requests are retried while the server is unavailable.
+func (s *RemoteCarService) call(idempotent bool, request func(ctx context.Context) error) error {
+	attempts := 1
+	if idempotent && s.config.Attempts > 1 {
+		attempts = s.config.Attempts
+	}
+
+	var err error
+	delay := s.config.Backoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+		err = request(ctx)
+		cancel()
+
+		if err == nil || !retryable(err) || attempt >= attempts {
+			break
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	return remoteError(err)
+}
+
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// remoteError maps gRPC codes back to the errors of the service.
+func remoteError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("car server: %w", ErrNotFound)
+	case codes.Aborted:
+		return fmt.Errorf("car server: %w", ErrConflict)
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return fmt.Errorf("%v: %w", err, ErrUnavailable)
+	default:
+		return err
+	}
+}
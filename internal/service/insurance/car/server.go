@@ -0,0 +1,93 @@
+package car
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	carpb "github.com/ozonmp/omp-bot/pkg/insurance/car/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server serves a CarService over gRPC, RemoteCarService is its client.
+type Server struct {
+	carpb.UnimplementedCarServiceServer
+	service CarService
+}
+
+func NewServer(service CarService) *Server {
+	return &Server{service: service}
+}
+
+func (s *Server) DescribeCar(_ context.Context, req *carpb.DescribeCarRequest) (*carpb.DescribeCarResponse, error) {
+	car, err := s.service.Describe(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &carpb.DescribeCarResponse{Car: carToProto(*car)}, nil
+}
+
+func (s *Server) ListCars(_ context.Context, req *carpb.ListCarsRequest) (*carpb.ListCarsResponse, error) {
+	if req.GetLimit() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must be positive")
+	}
+
+	cars, err := s.service.ListOrdered(ParseOrder(req.GetOrder()), req.GetCursor(), req.GetLimit())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &carpb.ListCarsResponse{Cars: make([]*carpb.Car, 0, len(cars))}
+	for _, car := range cars {
+		resp.Cars = append(resp.Cars, carToProto(car))
+	}
+
+	return resp, nil
+}
+
+func (s *Server) CreateCar(_ context.Context, req *carpb.CreateCarRequest) (*carpb.CreateCarResponse, error) {
+	if strings.TrimSpace(req.GetCar().GetTitle()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "car title is required")
+	}
+
+	id, err := s.service.Create(carFromProto(req.GetCar()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &carpb.CreateCarResponse{Id: id}, nil
+}
+
+func (s *Server) UpdateCar(_ context.Context, req *carpb.UpdateCarRequest) (*carpb.UpdateCarResponse, error) {
+	if strings.TrimSpace(req.GetCar().GetTitle()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "car title is required")
+	}
+
+	if err := s.service.Update(req.GetId(), carFromProto(req.GetCar())); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &carpb.UpdateCarResponse{}, nil
+}
+
+func (s *Server) RemoveCar(_ context.Context, req *carpb.RemoveCarRequest) (*carpb.RemoveCarResponse, error) {
+	if _, err := s.service.Remove(req.GetId()); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &carpb.RemoveCarResponse{}, nil
+}
+
+// statusError maps service errors to gRPC codes, RemoteCarService maps them back.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -1,76 +1,104 @@
 package car
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/ozonmp/omp-bot/internal/model/insurance"
 )
 
-type CarService interface {
-	Describe(carID uint64) (*insurance.Car, error)
-	List(cursor uint64, limit uint64) ([]insurance.Car, error)
-	Create(insurance.Car) (uint64, error)
-	Update(carID uint64, car insurance.Car) error
-	Remove(carID uint64) (bool, error)
+var (
+	ErrNotFound = errors.New("car not found")
+	// ErrUnavailable is returned by remote services when the server cannot be reached in time.
+	ErrUnavailable = errors.New("car service is unavailable")
+	// ErrConflict is returned by Update when the car has been changed since the given version was read.
+	ErrConflict = errors.New("car has been changed concurrently")
+)
+
+// Order is the order of listed cars.
+type Order string
+
+const (
+	OrderByID     Order = "id"
+	OrderByIDDesc Order = "-id"
+	OrderByTitle  Order = "title"
+)
+
+// Orders lists the supported orders.
+var Orders = []Order{OrderByID, OrderByIDDesc, OrderByTitle}
+
+// ParseOrder returns OrderByID for unknown names.
+func ParseOrder(name string) Order {
+	for _, order := range Orders {
+		if string(order) == name {
+			return order
+		}
+	}
+
+	return OrderByID
 }
 
-func (d DummyCarService) Describe(carID uint64) (*insurance.Car, error) {
-	if carID >= uint64(len(d.storage)) {
-		return nil, fmt.Errorf("no car with id %d", carID)
+// sortCars sorts cars listed by ID in the order.
+func sortCars(cars []insurance.Car, order Order) {
+	switch order {
+	case OrderByIDDesc:
+		for i, j := 0, len(cars)-1; i < j; i, j = i+1, j-1 {
+			cars[i], cars[j] = cars[j], cars[i]
+		}
+	case OrderByTitle:
+		sort.SliceStable(cars, func(i, j int) bool {
+			return strings.ToLower(cars[i].Title) < strings.ToLower(cars[j].Title)
+		})
 	}
-	return &d.storage[carID], nil
 }
 
-func (d DummyCarService) List(cursor uint64, limit uint64) ([]insurance.Car, error) {
-	if cursor >= uint64(len(d.storage)) {
-		return nil, fmt.Errorf("no car with id %d", cursor)
+// page returns up to limit cars starting from the cursor-th one.
+func page(cars []insurance.Car, cursor, limit uint64) []insurance.Car {
+	if cursor >= uint64(len(cars)) {
+		return []insurance.Car{}
 	}
-	high := uint64(len(d.storage))
-	if cursor + limit < high {
+	high := uint64(len(cars))
+	if limit < high-cursor {
 		high = cursor + limit
 	}
-	return d.storage[cursor:high], nil
-}
 
-func (d *DummyCarService) Create(car insurance.Car) (uint64, error) {
-	d.storage = append(d.storage, car)
-	return uint64(len(d.storage) - 1), nil
+	return cars[cursor:high]
 }
 
-func (d *DummyCarService) Update(carID uint64, car insurance.Car) error {
-	if carID >= uint64(len(d.storage)) {
-		return fmt.Errorf("no car with id %d", carID)
-	}
-	d.storage[carID] = car
-	return nil
+// CarService stores cars. Implementations must be safe for concurrent use
+// and must not share returned values with their storage.
+type CarService interface {
+	Describe(carID uint64) (*insurance.Car, error)
+	// List returns up to limit cars starting from the cursor-th one, the result is empty past the end.
+	List(cursor uint64, limit uint64) ([]insurance.Car, error)
+	// ListOrdered is like List with cars sorted in the order.
+	ListOrdered(order Order, cursor uint64, limit uint64) ([]insurance.Car, error)
+	// Create stores the car under a new ID and returns it, car.ID is ignored and the version is set to 1.
+	Create(insurance.Car) (uint64, error)
+	// Update replaces the car if car.Version is the current version, which is then incremented.
+	Update(carID uint64, car insurance.Car) error
+	Remove(carID uint64) (bool, error)
 }
 
-func (d *DummyCarService) Remove(carID uint64) (bool, error) {
-	if carID >= uint64(len(d.storage)) {
-		return false, fmt.Errorf("no car with id %d", carID)
+// checkVersion tells whether the update was made over the stored version of the car.
+func checkVersion(stored, updated insurance.Car) error {
+	if updated.Version != stored.Version {
+		return fmt.Errorf("car %d has version %d, the update is over version %d: %w",
+			stored.ID, stored.Version, updated.Version, ErrConflict)
 	}
-	copy(d.storage[carID:], d.storage[carID+1:])
-	d.storage = d.storage[:len(d.storage)-1]
-	return true, nil
-}
 
-type DummyCarService struct {
-	storage []insurance.Car
+	return nil
 }
 
-func NewDummyCarService() *DummyCarService {
-	return &DummyCarService{
-		storage: []insurance.Car{
-			{Title: "Toyota"},
-			{Title: "Nissan"},
-			{Title: "Infinity"},
-			{Title: "Mazda"},
-			{Title: "Honda"},
-			{Title: "Lexus"},
-			{Title: "Acura"},
-			{Title: "Suzuki"},
-			{Title: "Isuzu"},
-			{Title: "Mitsubishi"},
-			{Title: "Subaru"},
-		},
+// clone copies the saved quote, so callers never share it with the storage.
+func clone(car insurance.Car) insurance.Car {
+	if car.Quote != nil {
+		quote := *car.Quote
+		quote.Items = append([]insurance.QuoteItem(nil), quote.Items...)
+		car.Quote = &quote
 	}
+
+	return car
 }
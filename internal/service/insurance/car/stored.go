@@ -0,0 +1,213 @@
+package car
+
+import (
+	"fmt"
+
+	"github.com/ozonmp/omp-bot/internal/events"
+	"github.com/ozonmp/omp-bot/internal/events/outbox"
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+const (
+	carBucket = "insurance.car"
+	// sequenceBucket keeps the last issued IDs, so IDs of removed cars are never reused.
+	sequenceBucket = "sequence"
+)
+
+// StoredCarService keeps cars in the storage shared with other data of the bot.
+// Events of the changes are recorded to the outbox in the same transactions, a nil outbox records nothing.
+type StoredCarService struct {
+	store  storage.Store
+	outbox *outbox.Outbox
+}
+
+func NewStoredCarService(store storage.Store, box *outbox.Outbox) *StoredCarService {
+	return &StoredCarService{store: store, outbox: box}
+}
+
+// carKey pads IDs so keys are sorted like numbers.
+func carKey(id uint64) string {
+	return fmt.Sprintf("%020d", id)
+}
+
+func (s *StoredCarService) Describe(carID uint64) (*insurance.Car, error) {
+	var car insurance.Car
+	err := s.store.View(func(tx storage.Tx) error {
+		found, err := tx.Get(carBucket, carKey(carID), &car)
+		if err == nil && !found {
+			err = fmt.Errorf("no car with id %d: %w", carID, ErrNotFound)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &car, nil
+}
+
+func (s *StoredCarService) List(cursor uint64, limit uint64) ([]insurance.Car, error) {
+	cars := []insurance.Car{}
+	err := s.store.View(func(tx storage.Tx) error {
+		keys, err := tx.Keys(carBucket)
+		if err != nil {
+			return err
+		}
+
+		if cursor >= uint64(len(keys)) {
+			return nil
+		}
+		keys = keys[cursor:]
+		if limit < uint64(len(keys)) {
+			keys = keys[:limit]
+		}
+
+		for _, key := range keys {
+			var car insurance.Car
+			if _, err := tx.Get(carBucket, key, &car); err != nil {
+				return err
+			}
+			cars = append(cars, car)
+		}
+
+		return nil
+	})
+
+	return cars, err
+}
+
+func (s *StoredCarService) ListOrdered(order Order, cursor uint64, limit uint64) ([]insurance.Car, error) {
+	if order == OrderByID {
+		return s.List(cursor, limit)
+	}
+
+	cars, err := s.List(0, ^uint64(0))
+	if err != nil {
+		return nil, err
+	}
+	sortCars(cars, order)
+
+	return page(cars, cursor, limit), nil
+}
+
+func (s *StoredCarService) Create(car insurance.Car) (uint64, error) {
+	err := s.store.Update(func(tx storage.Tx) error {
+		var lastID uint64
+		if _, err := tx.Get(sequenceBucket, carBucket, &lastID); err != nil {
+			return err
+		}
+
+		car.ID, car.Version = lastID+1, 1
+		if err := tx.Put(sequenceBucket, carBucket, car.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Put(carBucket, carKey(car.ID), car); err != nil {
+			return err
+		}
+
+		return s.record(tx, CarCreated{Car: car})
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.notify()
+
+	return car.ID, nil
+}
+
+// Seed fills an empty storage with the cars, e.g. the sample ones. It records no events, nothing has changed yet.
+func (s *StoredCarService) Seed(cars ...insurance.Car) error {
+	return s.store.Update(func(tx storage.Tx) error {
+		var lastID uint64
+		if _, err := tx.Get(sequenceBucket, carBucket, &lastID); err != nil || lastID != 0 {
+			return err
+		}
+
+		for _, car := range cars {
+			lastID++
+			car.ID, car.Version = lastID, 1
+			if err := tx.Put(carBucket, carKey(car.ID), car); err != nil {
+				return err
+			}
+		}
+
+		return tx.Put(sequenceBucket, carBucket, lastID)
+	})
+}
+
+func (s *StoredCarService) Update(carID uint64, car insurance.Car) error {
+	err := s.store.Update(func(tx storage.Tx) error {
+		var stored insurance.Car
+		found, err := tx.Get(carBucket, carKey(carID), &stored)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("no car with id %d: %w", carID, ErrNotFound)
+		}
+
+		if err := checkVersion(stored, car); err != nil {
+			return err
+		}
+
+		car.ID = carID
+		car.Version++
+		if err := tx.Put(carBucket, carKey(carID), car); err != nil {
+			return err
+		}
+
+		return s.record(tx, CarUpdated{Before: stored, After: car})
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+
+	return nil
+}
+
+func (s *StoredCarService) Remove(carID uint64) (bool, error) {
+	err := s.store.Update(func(tx storage.Tx) error {
+		var stored insurance.Car
+		found, err := tx.Get(carBucket, carKey(carID), &stored)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("no car with id %d: %w", carID, ErrNotFound)
+		}
+
+		if err := tx.Delete(carBucket, carKey(carID)); err != nil {
+			return err
+		}
+
+		return s.record(tx, CarRemoved{Car: stored})
+	})
+	if err != nil {
+		return false, err
+	}
+	s.notify()
+
+	return true, nil
+}
+
+func (s *StoredCarService) record(tx storage.Tx, event events.Event) error {
+	if s.outbox == nil {
+		return nil
+	}
+
+	return s.outbox.Record(tx, event)
+}
+
+func (s *StoredCarService) notify() {
+	if s.outbox != nil {
+		s.outbox.Notify()
+	}
+}
+
+func (s *StoredCarService) Ping() error {
+	return s.store.Ping()
+}
@@ -0,0 +1,110 @@
+package claim
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+// MemoryClaimService keeps claims in memory, IDs are never reused.
+type MemoryClaimService struct {
+	mu     sync.RWMutex
+	claims map[uint64]insurance.Claim
+	ids    []uint64
+	lastID uint64
+}
+
+func NewMemoryClaimService(claims ...insurance.Claim) *MemoryClaimService {
+	s := &MemoryClaimService{
+		claims: make(map[uint64]insurance.Claim, len(claims)),
+	}
+	for _, claim := range claims {
+		s.create(claim)
+	}
+
+	return s
+}
+
+func (s *MemoryClaimService) Describe(claimID uint64) (*insurance.Claim, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	claim, found := s.claims[claimID]
+	if !found {
+		return nil, fmt.Errorf("no claim with id %d: %w", claimID, ErrNotFound)
+	}
+	claim = clone(claim)
+
+	return &claim, nil
+}
+
+func (s *MemoryClaimService) List(cursor uint64, limit uint64) ([]insurance.Claim, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if cursor >= uint64(len(s.ids)) {
+		return []insurance.Claim{}, nil
+	}
+	high := uint64(len(s.ids))
+	if limit < high-cursor {
+		high = cursor + limit
+	}
+
+	claims := make([]insurance.Claim, 0, high-cursor)
+	for _, id := range s.ids[cursor:high] {
+		claims = append(claims, clone(s.claims[id]))
+	}
+
+	return claims, nil
+}
+
+func (s *MemoryClaimService) Create(claim insurance.Claim) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claim.Status = insurance.ClaimFiled
+
+	return s.create(claim), nil
+}
+
+func (s *MemoryClaimService) create(claim insurance.Claim) uint64 {
+	s.lastID++
+	claim.ID = s.lastID
+	s.claims[claim.ID] = clone(claim)
+	s.ids = append(s.ids, claim.ID)
+
+	return claim.ID
+}
+
+func (s *MemoryClaimService) Transition(claimID uint64, next insurance.ClaimStatus) (*insurance.Claim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claim, found := s.claims[claimID]
+	if !found {
+		return nil, fmt.Errorf("no claim with id %d: %w", claimID, ErrNotFound)
+	}
+	if err := claim.Transition(next); err != nil {
+		return nil, err
+	}
+	s.claims[claimID] = claim
+	claim = clone(claim)
+
+	return &claim, nil
+}
+
+func (s *MemoryClaimService) AddPhotos(claimID uint64, fileIDs ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claim, found := s.claims[claimID]
+	if !found {
+		return fmt.Errorf("no claim with id %d: %w", claimID, ErrNotFound)
+	}
+	claim = clone(claim)
+	claim.Photos = append(claim.Photos, fileIDs...)
+	s.claims[claimID] = claim
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package claim
+
+import (
+	"errors"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+var ErrNotFound = errors.New("claim not found")
+
+// ClaimService stores claims. Implementations must be safe for concurrent use
+// and must not share returned values with their storage.
+type ClaimService interface {
+	Describe(claimID uint64) (*insurance.Claim, error)
+	// List returns up to limit claims starting from the cursor-th one, the result is empty past the end.
+	List(cursor uint64, limit uint64) ([]insurance.Claim, error)
+	// Create files the claim under a new ID and returns it, claim.ID and claim.Status are ignored.
+	Create(claim insurance.Claim) (uint64, error)
+	// Transition moves the claim to the next status, forbidden changes fail with *insurance.TransitionError.
+	Transition(claimID uint64, next insurance.ClaimStatus) (*insurance.Claim, error)
+	// AddPhotos attaches Telegram file IDs to the claim.
+	AddPhotos(claimID uint64, fileIDs ...string) error
+}
+
+// clone copies the photos, so callers never share them with the storage.
+func clone(claim insurance.Claim) insurance.Claim {
+	claim.Photos = append([]string(nil), claim.Photos...)
+
+	return claim
+}
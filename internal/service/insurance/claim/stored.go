@@ -0,0 +1,133 @@
+package claim
+
+import (
+	"fmt"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+const (
+	claimBucket    = "insurance.claim"
+	sequenceBucket = "sequence"
+)
+
+// StoredClaimService keeps claims in the storage shared with other data of the bot.
+type StoredClaimService struct {
+	store storage.Store
+}
+
+func NewStoredClaimService(store storage.Store) *StoredClaimService {
+	return &StoredClaimService{store: store}
+}
+
+func claimKey(id uint64) string {
+	return fmt.Sprintf("%020d", id)
+}
+
+func get(tx storage.Tx, claimID uint64) (insurance.Claim, error) {
+	var claim insurance.Claim
+	found, err := tx.Get(claimBucket, claimKey(claimID), &claim)
+	if err == nil && !found {
+		err = fmt.Errorf("no claim with id %d: %w", claimID, ErrNotFound)
+	}
+
+	return claim, err
+}
+
+func (s *StoredClaimService) Describe(claimID uint64) (*insurance.Claim, error) {
+	var claim insurance.Claim
+	err := s.store.View(func(tx storage.Tx) (err error) {
+		claim, err = get(tx, claimID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &claim, nil
+}
+
+func (s *StoredClaimService) List(cursor uint64, limit uint64) ([]insurance.Claim, error) {
+	claims := []insurance.Claim{}
+	err := s.store.View(func(tx storage.Tx) error {
+		keys, err := tx.Keys(claimBucket)
+		if err != nil {
+			return err
+		}
+
+		if cursor >= uint64(len(keys)) {
+			return nil
+		}
+		keys = keys[cursor:]
+		if limit < uint64(len(keys)) {
+			keys = keys[:limit]
+		}
+
+		for _, key := range keys {
+			var claim insurance.Claim
+			if _, err := tx.Get(claimBucket, key, &claim); err != nil {
+				return err
+			}
+			claims = append(claims, claim)
+		}
+
+		return nil
+	})
+
+	return claims, err
+}
+
+func (s *StoredClaimService) Create(claim insurance.Claim) (uint64, error) {
+	err := s.store.Update(func(tx storage.Tx) error {
+		var lastID uint64
+		if _, err := tx.Get(sequenceBucket, claimBucket, &lastID); err != nil {
+			return err
+		}
+
+		claim.ID = lastID + 1
+		claim.Status = insurance.ClaimFiled
+		if err := tx.Put(sequenceBucket, claimBucket, claim.ID); err != nil {
+			return err
+		}
+
+		return tx.Put(claimBucket, claimKey(claim.ID), claim)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return claim.ID, nil
+}
+
+func (s *StoredClaimService) Transition(claimID uint64, next insurance.ClaimStatus) (*insurance.Claim, error) {
+	var claim insurance.Claim
+	err := s.store.Update(func(tx storage.Tx) (err error) {
+		claim, err = get(tx, claimID)
+		if err != nil {
+			return err
+		}
+		if err := claim.Transition(next); err != nil {
+			return err
+		}
+
+		return tx.Put(claimBucket, claimKey(claimID), claim)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &claim, nil
+}
+
+func (s *StoredClaimService) AddPhotos(claimID uint64, fileIDs ...string) error {
+	return s.store.Update(func(tx storage.Tx) error {
+		claim, err := get(tx, claimID)
+		if err != nil {
+			return err
+		}
+		claim.Photos = append(claim.Photos, fileIDs...)
+
+		return tx.Put(claimBucket, claimKey(claimID), claim)
+	})
+}
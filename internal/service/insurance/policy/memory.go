@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+// MemoryPolicyService keeps policies in memory, IDs are never reused.
+type MemoryPolicyService struct {
+	mu       sync.RWMutex
+	policies map[uint64]insurance.Policy
+	ids      []uint64
+	lastID   uint64
+}
+
+func NewMemoryPolicyService(policies ...insurance.Policy) *MemoryPolicyService {
+	s := &MemoryPolicyService{
+		policies: make(map[uint64]insurance.Policy, len(policies)),
+	}
+	for _, policy := range policies {
+		s.create(policy)
+	}
+
+	return s
+}
+
+func (s *MemoryPolicyService) Describe(policyID uint64) (*insurance.Policy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policy, found := s.policies[policyID]
+	if !found {
+		return nil, fmt.Errorf("no policy with id %d: %w", policyID, ErrNotFound)
+	}
+	policy = clone(policy)
+
+	return &policy, nil
+}
+
+func (s *MemoryPolicyService) List(cursor uint64, limit uint64) ([]insurance.Policy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if cursor >= uint64(len(s.ids)) {
+		return []insurance.Policy{}, nil
+	}
+	high := uint64(len(s.ids))
+	if limit < high-cursor {
+		high = cursor + limit
+	}
+
+	policies := make([]insurance.Policy, 0, high-cursor)
+	for _, id := range s.ids[cursor:high] {
+		policies = append(policies, clone(s.policies[id]))
+	}
+
+	return policies, nil
+}
+
+func (s *MemoryPolicyService) Create(policy insurance.Policy) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.create(policy), nil
+}
+
+func (s *MemoryPolicyService) create(policy insurance.Policy) uint64 {
+	s.lastID++
+	policy.ID = s.lastID
+	s.policies[policy.ID] = clone(policy)
+	s.ids = append(s.ids, policy.ID)
+
+	return policy.ID
+}
+
+func (s *MemoryPolicyService) Update(policyID uint64, policy insurance.Policy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.policies[policyID]; !found {
+		return fmt.Errorf("no policy with id %d: %w", policyID, ErrNotFound)
+	}
+	policy.ID = policyID
+	s.policies[policyID] = clone(policy)
+
+	return nil
+}
+
+func (s *MemoryPolicyService) Remove(policyID uint64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.policies[policyID]; !found {
+		return false, fmt.Errorf("no policy with id %d: %w", policyID, ErrNotFound)
+	}
+	delete(s.policies, policyID)
+
+	i := sort.Search(len(s.ids), func(i int) bool { return s.ids[i] >= policyID })
+	s.ids = append(s.ids[:i], s.ids[i+1:]...)
+
+	return true, nil
+}
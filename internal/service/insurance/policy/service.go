@@ -0,0 +1,28 @@
+package policy
+
+import (
+	"errors"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+var ErrNotFound = errors.New("policy not found")
+
+// PolicyService stores policies. Implementations must be safe for concurrent use
+// and must not share returned values with their storage.
+type PolicyService interface {
+	Describe(policyID uint64) (*insurance.Policy, error)
+	// List returns up to limit policies starting from the cursor-th one, the result is empty past the end.
+	List(cursor uint64, limit uint64) ([]insurance.Policy, error)
+	// Create stores the policy under a new ID and returns it, policy.ID is ignored.
+	Create(policy insurance.Policy) (uint64, error)
+	Update(policyID uint64, policy insurance.Policy) error
+	Remove(policyID uint64) (bool, error)
+}
+
+// clone copies the car IDs, so callers never share them with the storage.
+func clone(policy insurance.Policy) insurance.Policy {
+	policy.CarIDs = append([]uint64(nil), policy.CarIDs...)
+
+	return policy
+}
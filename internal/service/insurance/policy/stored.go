@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+	"github.com/ozonmp/omp-bot/internal/storage"
+)
+
+const (
+	policyBucket   = "insurance.policy"
+	sequenceBucket = "sequence"
+)
+
+// StoredPolicyService keeps policies in the storage shared with other data of the bot.
+type StoredPolicyService struct {
+	store storage.Store
+}
+
+func NewStoredPolicyService(store storage.Store) *StoredPolicyService {
+	return &StoredPolicyService{store: store}
+}
+
+func policyKey(id uint64) string {
+	return fmt.Sprintf("%020d", id)
+}
+
+func (s *StoredPolicyService) Describe(policyID uint64) (*insurance.Policy, error) {
+	var policy insurance.Policy
+	err := s.store.View(func(tx storage.Tx) error {
+		found, err := tx.Get(policyBucket, policyKey(policyID), &policy)
+		if err == nil && !found {
+			err = fmt.Errorf("no policy with id %d: %w", policyID, ErrNotFound)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (s *StoredPolicyService) List(cursor uint64, limit uint64) ([]insurance.Policy, error) {
+	policies := []insurance.Policy{}
+	err := s.store.View(func(tx storage.Tx) error {
+		keys, err := tx.Keys(policyBucket)
+		if err != nil {
+			return err
+		}
+
+		if cursor >= uint64(len(keys)) {
+			return nil
+		}
+		keys = keys[cursor:]
+		if limit < uint64(len(keys)) {
+			keys = keys[:limit]
+		}
+
+		for _, key := range keys {
+			var policy insurance.Policy
+			if _, err := tx.Get(policyBucket, key, &policy); err != nil {
+				return err
+			}
+			policies = append(policies, policy)
+		}
+
+		return nil
+	})
+
+	return policies, err
+}
+
+func (s *StoredPolicyService) Create(policy insurance.Policy) (uint64, error) {
+	err := s.store.Update(func(tx storage.Tx) error {
+		var lastID uint64
+		if _, err := tx.Get(sequenceBucket, policyBucket, &lastID); err != nil {
+			return err
+		}
+
+		policy.ID = lastID + 1
+		if err := tx.Put(sequenceBucket, policyBucket, policy.ID); err != nil {
+			return err
+		}
+
+		return tx.Put(policyBucket, policyKey(policy.ID), policy)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return policy.ID, nil
+}
+
+func (s *StoredPolicyService) Update(policyID uint64, policy insurance.Policy) error {
+	return s.store.Update(func(tx storage.Tx) error {
+		var stored insurance.Policy
+		found, err := tx.Get(policyBucket, policyKey(policyID), &stored)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("no policy with id %d: %w", policyID, ErrNotFound)
+		}
+
+		policy.ID = policyID
+
+		return tx.Put(policyBucket, policyKey(policyID), policy)
+	})
+}
+
+func (s *StoredPolicyService) Remove(policyID uint64) (bool, error) {
+	err := s.store.Update(func(tx storage.Tx) error {
+		var stored insurance.Policy
+		found, err := tx.Get(policyBucket, policyKey(policyID), &stored)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("no policy with id %d: %w", policyID, ErrNotFound)
+		}
+
+		return tx.Delete(policyBucket, policyKey(policyID))
+	})
+
+	return err == nil, err
+}
@@ -0,0 +1,40 @@
+package rating
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+// ErrUnknownYear is returned for cars without the model year.
+var ErrUnknownYear = errors.New("the model year of the car is unknown")
+
+// UnknownValueError is returned when the rate tables have no rate for the attribute value.
+type UnknownValueError struct {
+	Attribute string
+	Value     string
+	Known     []string
+}
+
+func (e *UnknownValueError) Error() string {
+	return fmt.Sprintf("no rate for %s %q, known values: %s", e.Attribute, e.Value, strings.Join(e.Known, ", "))
+}
+
+// Request describes what is insured, the make and the year are taken from the car.
+type Request struct {
+	Car        insurance.Car
+	DriverAge  int
+	Region     string
+	Coverage   insurance.Coverage
+	Deductible insurance.Money
+	// At is the date of the quote, the age of the car is counted up to it.
+	At time.Time
+}
+
+// Engine calculates premiums. Implementations must be safe for concurrent use.
+type Engine interface {
+	Quote(request Request) (insurance.Quote, error)
+}
@@ -0,0 +1,72 @@
+package rating
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ozonmp/omp-bot/internal/model/insurance"
+)
+
+// TableEngine multiplies the base rate of the coverage by the factors of the rate tables.
+type TableEngine struct {
+	tables Tables
+}
+
+func NewTableEngine(tables Tables) *TableEngine {
+	return &TableEngine{tables: tables}
+}
+
+func (e *TableEngine) Quote(request Request) (insurance.Quote, error) {
+	base, found := e.tables.Base[string(request.Coverage)]
+	if !found {
+		return insurance.Quote{}, &UnknownValueError{Attribute: "coverage", Value: string(request.Coverage), Known: keys(e.tables.Base)}
+	}
+	region := strings.ToLower(request.Region)
+	regionFactor, found := e.tables.Region[region]
+	if !found {
+		return insurance.Quote{}, &UnknownValueError{Attribute: "region", Value: request.Region, Known: keys(e.tables.Region)}
+	}
+	if request.Car.Year == 0 {
+		return insurance.Quote{}, ErrUnknownYear
+	}
+
+	carAge := request.At.Year() - request.Car.Year
+	if carAge < 0 {
+		carAge = 0
+	}
+	carMake := strings.ToLower(request.Car.Make)
+	makeFactor, found := e.tables.Make[carMake]
+	if !found {
+		makeFactor = 1
+	}
+
+	quote := insurance.Quote{
+		Coverage:   request.Coverage,
+		Deductible: request.Deductible,
+		DriverAge:  request.DriverAge,
+		Region:     region,
+		QuotedAt:   request.At,
+	}
+	premium := insurance.MoneyFromFloat(base)
+	quote.Items = append(quote.Items, insurance.QuoteItem{Name: "base", Detail: string(request.Coverage), Factor: 1, Amount: premium})
+
+	adjust := func(name, detail string, factor float64) {
+		adjusted := insurance.Money(math.Round(float64(premium) * factor))
+		quote.Items = append(quote.Items, insurance.QuoteItem{Name: name, Detail: detail, Factor: factor, Amount: adjusted - premium})
+		premium = adjusted
+	}
+	adjust("car_age", strconv.Itoa(carAge), factor(e.tables.CarAge, float64(carAge)))
+	adjust("make", carMake, makeFactor)
+	adjust("driver_age", strconv.Itoa(request.DriverAge), factor(e.tables.DriverAge, float64(request.DriverAge)))
+	adjust("region", region, regionFactor)
+	adjust("deductible", request.Deductible.String(), factor(e.tables.Deductible, float64(request.Deductible)/100))
+
+	if minimum := insurance.MoneyFromFloat(e.tables.Minimum); premium < minimum {
+		quote.Items = append(quote.Items, insurance.QuoteItem{Name: "minimum", Detail: minimum.String(), Factor: 1, Amount: minimum - premium})
+		premium = minimum
+	}
+	quote.Premium = premium
+
+	return quote, nil
+}
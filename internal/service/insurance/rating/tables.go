@@ -0,0 +1,122 @@
+package rating
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+//go:embed rates.json
+var defaultTables []byte
+
+// Band applies Factor to values from From up to the From of the next band.
+type Band struct {
+	From   float64 `json:"from"`
+	Factor float64 `json:"factor"`
+}
+
+// Tables are the rates of TableEngine, amounts are in major units.
+type Tables struct {
+	// Base is the premium of each coverage before adjustments.
+	Base       map[string]float64 `json:"base"`
+	CarAge     []Band             `json:"car_age"`
+	DriverAge  []Band             `json:"driver_age"`
+	Deductible []Band             `json:"deductible"`
+	// Make and Region map lower case names to factors, unknown makes are rated with 1.
+	Make   map[string]float64 `json:"make"`
+	Region map[string]float64 `json:"region"`
+	// Minimum is the least premium of a quote.
+	Minimum float64 `json:"minimum"`
+}
+
+// DefaultTables returns the rate tables shipped with the bot.
+func DefaultTables() Tables {
+	tables, err := ParseTables(defaultTables)
+	if err != nil {
+		panic("rating: malformed default tables: " + err.Error())
+	}
+
+	return tables
+}
+
+// LoadTables reads the rate tables from a JSON file.
+func LoadTables(name string) (Tables, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return Tables{}, err
+	}
+
+	tables, err := ParseTables(data)
+	if err != nil {
+		return Tables{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return tables, nil
+}
+
+func ParseTables(data []byte) (Tables, error) {
+	var tables Tables
+	if err := json.Unmarshal(data, &tables); err != nil {
+		return Tables{}, err
+	}
+
+	if len(tables.Base) == 0 {
+		return Tables{}, fmt.Errorf("base rates are missing")
+	}
+	if len(tables.Region) == 0 {
+		return Tables{}, fmt.Errorf("region factors are missing")
+	}
+	for name, bands := range map[string][]Band{
+		"car_age":    tables.CarAge,
+		"driver_age": tables.DriverAge,
+		"deductible": tables.Deductible,
+	} {
+		if len(bands) == 0 {
+			return Tables{}, fmt.Errorf("%s bands are missing", name)
+		}
+		if !sort.SliceIsSorted(bands, func(i, j int) bool { return bands[i].From < bands[j].From }) {
+			return Tables{}, fmt.Errorf("%s bands are not sorted by from", name)
+		}
+		for _, band := range bands {
+			if band.Factor <= 0 {
+				return Tables{}, fmt.Errorf("%s band from %v has a non-positive factor", name, band.From)
+			}
+		}
+	}
+	tables.Make = lowerKeys(tables.Make)
+	tables.Region = lowerKeys(tables.Region)
+
+	return tables, nil
+}
+
+func lowerKeys(factors map[string]float64) map[string]float64 {
+	lowered := make(map[string]float64, len(factors))
+	for name, factor := range factors {
+		lowered[strings.ToLower(name)] = factor
+	}
+
+	return lowered
+}
+
+// factor returns the factor of the band the value falls in, values below the first band get its factor.
+func factor(bands []Band, value float64) float64 {
+	i := sort.Search(len(bands), func(i int) bool { return bands[i].From > value })
+	if i == 0 {
+		return bands[0].Factor
+	}
+
+	return bands[i-1].Factor
+}
+
+func keys(m map[string]float64) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileStore keeps data in memory and rewrites the whole JSON file on every committed transaction,
+// so a write costs time proportional to all the stored data. It is meant for a single bot instance with
+// a few thousand records, larger data needs a database. Transactions without changes do not touch the file.
+// The file is replaced atomically, so it is never left half written.
+type FileStore struct {
+	*MemoryStore
+	path string
+}
+
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{MemoryStore: NewMemoryStore(), path: path}
+
+	content, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(content, &s.data); err != nil {
+			return nil, fmt.Errorf("storage: malformed %s: %w", path, err)
+		}
+		if s.data == nil {
+			s.data = make(buckets)
+		}
+	}
+	s.commit = s.write
+
+	return s, nil
+}
+
+func (s *FileStore) write(data buckets) error {
+	content, err := json.MarshalIndent(data, "", " ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Ping checks that the directory of the file is still accessible.
+func (s *FileStore) Ping() error {
+	_, err := os.Stat(filepath.Dir(s.path))
+
+	return err
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrReadOnly = errors.New("storage: the transaction is read-only")
+
+type buckets map[string]map[string]json.RawMessage
+
+// MemoryStore keeps data in memory, FileStore builds on it.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data buckets
+	// commit is called with the data including the changes of a transaction before they are applied
+	commit func(data buckets) error
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: make(buckets)}
+}
+
+func (s *MemoryStore) View(fn func(tx Tx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return fn(&tx{data: s.data})
+}
+
+func (s *MemoryStore) Update(fn func(tx Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &tx{data: s.data, writable: true, changes: make(buckets)}
+	if err := fn(t); err != nil {
+		return err
+	}
+	if len(t.changes) == 0 {
+		return nil
+	}
+
+	next := t.merged()
+	if s.commit != nil {
+		if err := s.commit(next); err != nil {
+			return err
+		}
+	}
+	s.data = next
+
+	return nil
+}
+
+func (s *MemoryStore) Ping() error {
+	return nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// tx sees the committed data with its own changes on top, nil values in changes are deletions.
+type tx struct {
+	data     buckets
+	writable bool
+	changes  buckets
+}
+
+func (t *tx) Get(bucket, key string, dst interface{}) (bool, error) {
+	value, found := t.changes[bucket][key]
+	if !found {
+		value, found = t.data[bucket][key]
+	}
+	if !found || value == nil {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(value, dst)
+}
+
+func (t *tx) Put(bucket, key string, value interface{}) error {
+	encoded, err := encode(value)
+	if err != nil {
+		return err
+	}
+
+	return t.set(bucket, key, encoded)
+}
+
+func (t *tx) Delete(bucket, key string) error {
+	return t.set(bucket, key, nil)
+}
+
+func (t *tx) set(bucket, key string, value json.RawMessage) error {
+	if !t.writable {
+		return ErrReadOnly
+	}
+
+	if t.changes[bucket] == nil {
+		t.changes[bucket] = make(map[string]json.RawMessage)
+	}
+	t.changes[bucket][key] = value
+
+	return nil
+}
+
+func (t *tx) Keys(bucket string) ([]string, error) {
+	keys := make([]string, 0, len(t.data[bucket]))
+	for key := range t.data[bucket] {
+		if _, changed := t.changes[bucket][key]; !changed {
+			keys = append(keys, key)
+		}
+	}
+	for key, value := range t.changes[bucket] {
+		if value != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// merged returns the committed data with the changes applied, untouched buckets are shared.
+func (t *tx) merged() buckets {
+	next := make(buckets, len(t.data)+len(t.changes))
+	for name, bucket := range t.data {
+		next[name] = bucket
+	}
+
+	for name, changes := range t.changes {
+		bucket := make(map[string]json.RawMessage, len(t.data[name])+len(changes))
+		for key, value := range t.data[name] {
+			bucket[key] = value
+		}
+		for key, value := range changes {
+			if value == nil {
+				delete(bucket, key)
+			} else {
+				bucket[key] = value
+			}
+		}
+		next[name] = bucket
+	}
+
+	return next
+}
@@ -0,0 +1,49 @@
+// Package storage is a small transactional key-value store shared by domain data and user settings.
+// Values are kept as JSON documents in named buckets.
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Tx reads and writes values of one transaction.
+type Tx interface {
+	// Get decodes the value into dst and reports whether the key exists.
+	Get(bucket, key string, dst interface{}) (bool, error)
+	// Put stores the JSON encoding of the value, read-only transactions fail.
+	Put(bucket, key string, value interface{}) error
+	Delete(bucket, key string) error
+	// Keys returns the keys of the bucket in ascending order.
+	Keys(bucket string) ([]string, error)
+}
+
+type Store interface {
+	// View runs fn in a read-only transaction.
+	View(fn func(tx Tx) error) error
+	// Update runs fn in a read-write transaction, its changes are applied only if fn succeeds.
+	// Updates are serialized.
+	Update(fn func(tx Tx) error) error
+	Ping() error
+	Close() error
+}
+
+// DefaultFileName is the name of the file kept by Open in the data directory.
+const DefaultFileName = "bot.json"
+
+// Open returns the file store in the directory, or a memory store if the directory is empty.
+func Open(dir string) (Store, error) {
+	if dir == "" {
+		return NewMemoryStore(), nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return OpenFileStore(filepath.Join(dir, DefaultFileName))
+}
+
+func encode(value interface{}) (json.RawMessage, error) {
+	return json.Marshal(value)
+}